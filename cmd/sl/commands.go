@@ -0,0 +1,154 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/kingzbauer/scraperlang/cmdutil"
+	"github.com/kingzbauer/scraperlang/interpreter"
+	"github.com/kingzbauer/scraperlang/lsp"
+	"github.com/kingzbauer/scraperlang/repl"
+	"github.com/kingzbauer/scraperlang/token"
+)
+
+func runCmd(args []string) int {
+	flags := newFlagSet("run")
+	var emit emitFlags
+	flags.Var(&emit, "emit", "write the records of emit statements as `FORMAT[:PATH]`, FORMAT is jsonl or csv "+
+		"and PATH defaults to stdout. Repeatable, json lines on stdout when omitted")
+	verbose := flags.Bool("v", false, "log the requests to stderr")
+	src, code := parseArgs(flags, args)
+	if src == nil {
+		return code
+	}
+	ast, ok := src.Resolve(os.Stderr)
+	if !ok {
+		return cmdutil.ExitSyntax
+	}
+
+	sinks, formats, err := emit.sinks()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "sl run: %s\n", err)
+		return cmdutil.ExitUsage
+	}
+	opts := []interpreter.Option{interpreter.WithSinks(sinks...), interpreter.WithOutputSinks(formats...)}
+	if *verbose {
+		opts = append(opts, interpreter.WithLogger(log.New(os.Stderr, "", log.Ltime)))
+	}
+	i, err := interpreter.New(ast, opts...)
+	if err != nil {
+		for _, sink := range sinks {
+			sink.Close()
+		}
+		src.Printer().Print(os.Stderr, err)
+		return cmdutil.ExitSyntax
+	}
+	if err := i.Exec(); err != nil {
+		src.Printer().Print(os.Stderr, err)
+		return cmdutil.ExitRuntime
+	}
+	cmdutil.PrintDiagnostics(os.Stderr, src.Printer(), i.Err())
+	return cmdutil.ExitCode(i.Err())
+}
+
+func checkCmd(args []string) int {
+	src, code := parseArgs(newFlagSet("check"), args)
+	if src == nil {
+		return code
+	}
+	if _, ok := src.Resolve(os.Stderr); !ok {
+		return cmdutil.ExitSyntax
+	}
+	return cmdutil.ExitOK
+}
+
+func tokensCmd(args []string) int {
+	flags := newFlagSet("tokens")
+	asJSON := flags.Bool("json", false, "print the tokens as a JSON array")
+	comments := flags.Bool("comments", false, "include the comment tokens")
+	src, code := parseArgs(flags, args)
+	if src == nil {
+		return code
+	}
+	var opts []token.Option
+	if *comments {
+		opts = append(opts, token.WithComments())
+	}
+	tokens, ok := src.Scan(os.Stderr, opts...)
+	if !ok {
+		return cmdutil.ExitSyntax
+	}
+	if *asJSON {
+		return printJSON(tokens)
+	}
+	for _, t := range tokens {
+		fmt.Printf("%d:%d\t%s\t%q\n", t.Line+1, t.Column+1, t.Type, t.Lexeme)
+	}
+	return cmdutil.ExitOK
+}
+
+func astCmd(args []string) int {
+	flags := newFlagSet("ast")
+	asJSON := flags.Bool("json", false, "print the syntax tree as JSON")
+	src, code := parseArgs(flags, args)
+	if src == nil {
+		return code
+	}
+	ast, ok := src.Parse(os.Stderr)
+	if !ok {
+		return cmdutil.ExitSyntax
+	}
+	if *asJSON {
+		return printJSON(ast)
+	}
+	fmt.Println(ast)
+	return cmdutil.ExitOK
+}
+
+// printJSON writes the indented JSON encoding of the value to stdout
+func printJSON(val interface{}) int {
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(val); err != nil {
+		fmt.Fprintf(os.Stderr, "sl: %s\n", err)
+		return cmdutil.ExitRuntime
+	}
+	return cmdutil.ExitOK
+}
+
+func lspCmd(args []string) int {
+	flags := newFlagSet("lsp")
+	if err := flags.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return cmdutil.ExitOK
+		}
+		return cmdutil.ExitUsage
+	}
+	if err := lsp.NewServer(os.Stdin, os.Stdout).Serve(); err != nil {
+		fmt.Fprintf(os.Stderr, "sl lsp: %s\n", err)
+		return cmdutil.ExitRuntime
+	}
+	return cmdutil.ExitOK
+}
+
+func replCmd(args []string) int {
+	flags := newFlagSet("repl")
+	if err := flags.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return cmdutil.ExitOK
+		}
+		return cmdutil.ExitUsage
+	}
+	r, err := repl.New(os.Stdin, os.Stdout)
+	if err == nil {
+		err = r.Run()
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "sl repl: %s\n", err)
+		return cmdutil.ExitRuntime
+	}
+	return cmdutil.ExitOK
+}
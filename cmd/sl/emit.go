@@ -0,0 +1,54 @@
+package main
+
+import (
+	"strings"
+
+	"github.com/kingzbauer/scraperlang/cmdutil"
+	"github.com/kingzbauer/scraperlang/interpreter"
+)
+
+// emitFlags collects the outputs of the records of emit statements, every one given as
+// FORMAT[:PATH]. The records are written to stdout when the path is omitted or is "-"
+type emitFlags []string
+
+func (f *emitFlags) String() string {
+	return strings.Join(*f, ",")
+}
+
+func (f *emitFlags) Set(val string) error {
+	*f = append(*f, val)
+	return nil
+}
+
+// sinks creates a sink for every output written to a file and returns the formats of the outputs
+// written to stdout. Those are left to the interpreter so the records don't interleave with the
+// output of print statements. The sinks created so far are closed on error
+func (f emitFlags) sinks() ([]interpreter.Sink, []string, error) {
+	var sinks []interpreter.Sink
+	var formats []string
+	fail := func(err error) ([]interpreter.Sink, []string, error) {
+		for _, sink := range sinks {
+			sink.Close()
+		}
+		return nil, nil, err
+	}
+	for _, spec := range f {
+		format, path := spec, cmdutil.Stdout
+		if index := strings.Index(spec, ":"); index >= 0 {
+			format, path = spec[:index], spec[index+1:]
+		}
+		if err := interpreter.CheckFormat(format); err != nil {
+			return fail(err)
+		}
+		if path == "" || path == cmdutil.Stdout {
+			formats = append(formats, format)
+			continue
+		}
+		sink, err := interpreter.NewFileSink(path, format)
+		if err != nil {
+			return fail(err)
+		}
+		sinks = append(sinks, sink)
+	}
+	return sinks, formats, nil
+}
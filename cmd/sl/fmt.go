@@ -0,0 +1,66 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/kingzbauer/scraperlang/cmdutil"
+	"github.com/kingzbauer/scraperlang/printer"
+)
+
+func fmtCmd(args []string) int {
+	flags := newFlagSet("fmt")
+	write := flags.Bool("w", false, "write the result to the source file instead of stdout")
+	list := flags.Bool("l", false, "list the files whose formatting differs from the canonical one")
+	if err := flags.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return cmdutil.ExitOK
+		}
+		return cmdutil.ExitUsage
+	}
+	paths := flags.Args()
+	if len(paths) == 0 {
+		paths = []string{cmdutil.Stdin}
+	}
+
+	code := cmdutil.ExitOK
+	for _, path := range paths {
+		if *write && path == cmdutil.Stdin {
+			fmt.Fprintln(os.Stderr, "sl fmt: can't use -w with the standard input")
+			return cmdutil.ExitUsage
+		}
+		src, err := cmdutil.ReadSource(path)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "sl fmt: %s\n", err)
+			return cmdutil.ExitUsage
+		}
+		if _, ok := src.Parse(os.Stderr); !ok {
+			code = cmdutil.ExitSyntax
+			continue
+		}
+		formatted, err := printer.Format(src.Content)
+		if err != nil {
+			src.Printer().Print(os.Stderr, err)
+			code = cmdutil.ExitSyntax
+			continue
+		}
+
+		changed := !bytes.Equal(src.Content, formatted)
+		if *list && changed {
+			fmt.Println(src.Name)
+		}
+		if *write {
+			if changed {
+				if err := os.WriteFile(path, formatted, 0644); err != nil {
+					fmt.Fprintf(os.Stderr, "sl fmt: %s\n", err)
+					return cmdutil.ExitUsage
+				}
+			}
+		} else if !*list {
+			os.Stdout.Write(formatted)
+		}
+	}
+	return code
+}
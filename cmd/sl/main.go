@@ -0,0 +1,103 @@
+// Command sl runs and inspects scraperlang scripts.
+//
+// Usage:
+//
+//	sl <command> [flags] [file]
+//
+// The script is read from the standard input when the file is omitted or is "-".
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/kingzbauer/scraperlang/cmdutil"
+)
+
+// command is a single sl subcommand. It returns the exit code of the process
+type command struct {
+	usage string
+	run   func(args []string) int
+}
+
+var commands map[string]command
+
+// The commands are registered in init since their flag sets refer back to the registry
+func init() {
+	commands = map[string]command{
+		"run":    {usage: "execute a script", run: runCmd},
+		"check":  {usage: "report syntax and semantic errors without executing a script", run: checkCmd},
+		"fmt":    {usage: "format scripts in the canonical style", run: fmtCmd},
+		"lsp":    {usage: "start a language server speaking LSP over stdio", run: lspCmd},
+		"repl":   {usage: "evaluate statements and expressions interactively", run: replCmd},
+		"tokens": {usage: "print the tokens of a script", run: tokensCmd},
+		"ast":    {usage: "print the syntax tree of a script", run: astCmd},
+	}
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "Usage: sl <command> [flags] [file]")
+	fmt.Fprintln(os.Stderr, "\nCommands:")
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(os.Stderr, "  %-8s %s\n", name, commands[name].usage)
+	}
+	fmt.Fprintln(os.Stderr, "\nThe script is read from stdin when the file is omitted or is \"-\".")
+	fmt.Fprintln(os.Stderr, "Run 'sl <command> -h' for the flags of a command.")
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(cmdutil.ExitUsage)
+	}
+	name := os.Args[1]
+	if name == "-h" || name == "-help" || name == "--help" || name == "help" {
+		usage()
+		os.Exit(cmdutil.ExitOK)
+	}
+	cmd, ok := commands[name]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "sl: unknown command %q\n\n", name)
+		usage()
+		os.Exit(cmdutil.ExitUsage)
+	}
+	os.Exit(cmd.run(os.Args[2:]))
+}
+
+// newFlagSet creates the flag set of a subcommand. Flag errors are reported by the caller
+func newFlagSet(name string) *flag.FlagSet {
+	flags := flag.NewFlagSet("sl "+name, flag.ContinueOnError)
+	flags.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: sl %s [flags] [file]\n\n%s\n", name, commands[name].usage)
+		flags.PrintDefaults()
+	}
+	return flags
+}
+
+// parseArgs parses the flags of the subcommand and reads the script named by the only
+// positional argument
+func parseArgs(flags *flag.FlagSet, args []string) (*cmdutil.Source, int) {
+	if err := flags.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return nil, cmdutil.ExitOK
+		}
+		return nil, cmdutil.ExitUsage
+	}
+	if flags.NArg() > 1 {
+		fmt.Fprintf(os.Stderr, "%s: expects a single file, got %d\n", flags.Name(), flags.NArg())
+		return nil, cmdutil.ExitUsage
+	}
+	src, err := cmdutil.ReadSource(flags.Arg(0))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s: %s\n", flags.Name(), err)
+		return nil, cmdutil.ExitUsage
+	}
+	return src, cmdutil.ExitOK
+}
@@ -2,35 +2,128 @@ package cmdutil
 
 import (
 	"errors"
-	"fmt"
+	"io"
 	"os"
+
+	"github.com/kingzbauer/scraperlang/diag"
+	"github.com/kingzbauer/scraperlang/interpreter"
+	"github.com/kingzbauer/scraperlang/parser"
+	"github.com/kingzbauer/scraperlang/resolver"
+	"github.com/kingzbauer/scraperlang/token"
+)
+
+// Exit codes shared by the sl subcommands
+const (
+	ExitOK = iota
+	// ExitSyntax is used for scanner, parser and resolver errors
+	ExitSyntax
+	// ExitRuntime is used for runtime errors raised while executing a script
+	ExitRuntime
+	// ExitHTTP is used when some of the requests made by a script could not be completed
+	ExitHTTP
+	// ExitUsage is used for invalid subcommands, flags or arguments
+	ExitUsage = 64
 )
 
-// Common cmdutil errors
-var (
-	ErrFilenameNotFound error = errors.New("Filename not found")
+// Paths standing for the standard streams
+const (
+	// Stdin is the path used to read a script from the standard input
+	Stdin = "-"
+	// Stdout is the path used to write to the standard output
+	Stdout = "-"
 )
 
-// ReadFileArg checks the arguments passed to via the cli and reads the filename provided
-// exitOnArg boolean indicates whether to exit if the filename is provided. If false and
-// the filename has not been provided, it will return an error instead
-func ReadFileArg(exitOnArg bool) ([]byte, error) {
-	argsEnough := len(os.Args) > 1
-	if !argsEnough && exitOnArg {
-		fmt.Println("Expected filename")
-		os.Exit(1)
-	} else if !argsEnough {
-		return nil, ErrFilenameNotFound
+// Source is a script read from a file or the standard input
+type Source struct {
+	Name    string
+	Content []byte
+}
+
+// ReadSource reads the script at path. An empty path or Stdin reads the script from the
+// standard input
+func ReadSource(path string) (*Source, error) {
+	if path == "" || path == Stdin {
+		content, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			return nil, err
+		}
+		return &Source{Name: "<stdin>", Content: content}, nil
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
 	}
+	return &Source{Name: path, Content: content}, nil
+}
+
+// Printer returns a diagnostic printer for the source
+func (s *Source) Printer() *diag.Printer {
+	return diag.NewPrinter(s.Name, s.Content)
+}
 
-	filename := os.Args[1]
-	return os.ReadFile(filename)
+// Scan returns the tokens of the source. A scanner error is rendered to w
+func (s *Source) Scan(w io.Writer, opts ...token.Option) (token.Tokens, bool) {
+	tokens, err := token.NewScanner(s.Content, opts...).ScanTokens()
+	if err != nil {
+		s.Printer().Print(w, err)
+		return nil, false
+	}
+	return tokens, true
 }
 
-// ExitOnError will exit the application if the provided error is not nil
-func ExitOnError(err error) {
+// Parse scans and parses the source. Syntax errors are rendered to w
+func (s *Source) Parse(w io.Writer) ([]parser.Expr, bool) {
+	tokens, ok := s.Scan(w)
+	if !ok {
+		return nil, false
+	}
+	p := parser.New(tokens)
+	ast, err := p.Parse()
 	if err != nil {
-		fmt.Printf("Error: %s\n", err)
-		os.Exit(2)
+		s.Printer().Print(w, err)
+		return nil, false
+	}
+	if p.HasErrs() {
+		PrintDiagnostics(w, s.Printer(), p.Err())
+		return nil, false
+	}
+	return ast, true
+}
+
+// Resolve parses and resolves the source. Syntax errors, semantic errors and warnings are
+// rendered to w
+func (s *Source) Resolve(w io.Writer) ([]parser.Expr, bool) {
+	ast, ok := s.Parse(w)
+	if !ok {
+		return nil, false
+	}
+	r := resolver.New(ast)
+	r.Resolve()
+	PrintDiagnostics(w, s.Printer(), r.Warnings())
+	if r.HasErrs() {
+		PrintDiagnostics(w, s.Printer(), r.Err())
+		return nil, false
+	}
+	return ast, true
+}
+
+// PrintDiagnostics renders every error along with the source line it relates to
+func PrintDiagnostics(w io.Writer, printer *diag.Printer, errs []error) {
+	for _, err := range errs {
+		printer.Print(w, err)
+	}
+}
+
+// ExitCode returns the exit code for the errors raised while executing a script. Runtime errors
+// take precedence over failed requests
+func ExitCode(errs []error) int {
+	code := ExitOK
+	for _, err := range errs {
+		var requestErr interpreter.RequestError
+		if !errors.As(err, &requestErr) {
+			return ExitRuntime
+		}
+		code = ExitHTTP
 	}
+	return code
 }
@@ -0,0 +1,163 @@
+package diag
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Severity describes how serious a diagnostic is
+type Severity int
+
+// Diagnostic severities
+const (
+	Error Severity = iota
+	Warning
+)
+
+func (s Severity) String() string {
+	switch s {
+	case Warning:
+		return "warning"
+	default:
+		return "error"
+	}
+}
+
+// Position is a 0 based line and column within the source
+type Position struct {
+	Line, Column int
+}
+
+// NoPosition is used for diagnostics that don't relate to a specific location in the source
+var NoPosition = Position{Line: -1, Column: -1}
+
+// Diagnostic is a problem found in a script by any of the scanner, parser, resolver or interpreter.
+// The span runs from Start up to but not including End
+type Diagnostic struct {
+	File     string
+	Start    Position
+	End      Position
+	Severity Severity
+	Msg      string
+}
+
+// Diagnoser is implemented by the errors that can be described as a diagnostic
+type Diagnoser interface {
+	Diagnostic() Diagnostic
+}
+
+// New creates a diagnostic spanning the lexeme that starts at the provided line and column
+func New(severity Severity, line, column int, lexeme, msg string) Diagnostic {
+	d := Diagnostic{
+		Start:    Position{Line: line, Column: column},
+		End:      Position{Line: line, Column: column + len(lexeme)},
+		Severity: severity,
+		Msg:      msg,
+	}
+	if index := strings.LastIndexByte(lexeme, '\n'); index >= 0 {
+		d.End = Position{Line: line + strings.Count(lexeme, "\n"), Column: len(lexeme) - index - 1}
+	}
+	return d
+}
+
+// HasPosition checks whether the diagnostic relates to a specific location in the source
+func (d Diagnostic) HasPosition() bool {
+	return d.Start.Line >= 0
+}
+
+// Error implements the error interface. Lines and columns are 1 based
+func (d Diagnostic) Error() string {
+	if !d.HasPosition() {
+		if d.File != "" {
+			return fmt.Sprintf("%s: %s", d.File, d.Msg)
+		}
+		return d.Msg
+	}
+	if d.File != "" {
+		return fmt.Sprintf("%s:%d:%d: %s", d.File, d.Start.Line+1, d.Start.Column+1, d.Msg)
+	}
+	return fmt.Sprintf("[%d:%d] %s", d.Start.Line+1, d.Start.Column+1, d.Msg)
+}
+
+// From returns the diagnostic of the error. Errors that don't implement the Diagnoser interface
+// result in a diagnostic without a position
+func From(err error) Diagnostic {
+	var diagnoser Diagnoser
+	if errors.As(err, &diagnoser) {
+		return diagnoser.Diagnostic()
+	}
+	return Diagnostic{Start: NoPosition, End: NoPosition, Severity: Error, Msg: err.Error()}
+}
+
+// Printer renders diagnostics together with the offending source line and a caret underline
+// of the span
+type Printer struct {
+	file  string
+	lines [][]byte
+}
+
+// NewPrinter creates a printer for the diagnostics of the provided source file
+func NewPrinter(file string, src []byte) *Printer {
+	lines := bytes.Split(src, []byte("\n"))
+	for index, line := range lines {
+		lines[index] = bytes.TrimSuffix(line, []byte("\r"))
+	}
+	return &Printer{file: file, lines: lines}
+}
+
+// Print writes the rendered diagnostic of the error to w
+func (p *Printer) Print(w io.Writer, err error) {
+	io.WriteString(w, p.Format(err))
+}
+
+// Format renders the diagnostic of the error e.g
+//
+//	script.sl:3:9: error: Undefined variable "x"
+//	   3 |   print x
+//	     |         ^
+func (p *Printer) Format(err error) string {
+	d := From(err)
+	d.File = p.file
+	buf := &strings.Builder{}
+	if !d.HasPosition() {
+		fmt.Fprintf(buf, "%s: %s: %s\n", p.file, d.Severity, d.Msg)
+		return buf.String()
+	}
+	fmt.Fprintf(buf, "%s:%d:%d: %s: %s\n", p.file, d.Start.Line+1, d.Start.Column+1, d.Severity, d.Msg)
+	if d.Start.Line >= len(p.lines) {
+		return buf.String()
+	}
+
+	line := p.lines[d.Start.Line]
+	number := fmt.Sprint(d.Start.Line + 1)
+	gutter := strings.Repeat(" ", len(number))
+	fmt.Fprintf(buf, " %s | %s\n", number, line)
+
+	// The underline runs up to the end of the span or the end of the line for multiline spans
+	start := d.Start.Column
+	if start > len(line) {
+		start = len(line)
+	}
+	end := d.End.Column
+	if d.End.Line != d.Start.Line || end > len(line) {
+		end = len(line)
+	}
+	width := end - start
+	if width < 1 {
+		width = 1
+	}
+	// Tabs are kept so that the underline aligns with the source line
+	indent := make([]byte, start)
+	for index := range indent {
+		if line[index] == '\t' {
+			indent[index] = '\t'
+		} else {
+			indent[index] = ' '
+		}
+	}
+	fmt.Fprintf(buf, " %s | %s%s\n", gutter, indent, strings.Repeat("^", width))
+	return buf.String()
+}
@@ -3,15 +3,24 @@ package scraperlang
 /*
 	document				-> global_defs* ;
 	global_defs 		-> NEWLINE* tagged_closure* ( NEWLINE+ | EOF ) ;
-	tagged_closure	-> IDENT body ;
+	tagged_closure	-> IDENT ( "(" option ( "," option )* ")" )? body ;
+	option					-> IDENT ":" expression ;
 	body 						-> "{" ( NEWLINE+ expr_statements* )? "}" ;
-	builtin_funcs		-> ( getExpr | printExpr ) NEWLINE ;
-	expr_statements	-> ( assign | builtin_funcs | callExpr | attrFuncCall | returnStmt )  NEWLINE ;
+	builtin_funcs		-> ( getExpr | requestExpr | printExpr ) NEWLINE ;
+	expr_statements	-> ( assign | builtin_funcs | callExpr | attrFuncCall | returnStmt | ifStmt |
+										 forStmt | whileStmt | emitStmt | tryStmt | "break" | "continue" )  NEWLINE ;
 	getExpr					-> tag? "get" expression ("," expression) ;
+	requestExpr			-> tag? ( "post" | "put" | "patch" | "delete" | "head" ) expression
+										 ( "," expression ( "," expression )? )? ;
 	tag							-> "@"IDENT ;
 	printExpr				-> "print" expression ( "," expression )* ;
 	attrFuncCall		-> IDENT "." IDENT ( ( "(" argumentList? ")" ) |  argumentList ) ;
 	returnStmt			-> "return" expression? ;
+	ifStmt					-> "if" expression body ( "else" ( ifStmt | body ) )? ;
+	forStmt					-> "for" IDENT ( "," IDENT )? "in" expression body ;
+	whileStmt				-> "while" expression body ;
+	emitStmt				-> "emit" expression ;
+	tryStmt					-> "try" body "catch" IDENT body ;
 	closure					-> "(" params? ")" body ;
 	arrayExpr				-> "[" NEWLINE* expression NEWLINE* ( "," NEWLINE* expression NEWLINE* )* "]" ;
 	mapExpr					-> "{" NEWLINE* mapEntry NEWLINE* ( "," NEWLINE* mapEntry NEWLINE* )* "}" ;
@@ -19,10 +28,19 @@ package scraperlang
 
 	callExpr				-> IDENT expression ( "," expression )* ;
 	assign					-> IDENT "=" ( expression ) ;
-	expression 			-> htmlAttrAccessor ;
+	expression 			-> or ;
+	or							-> and ( "or" NEWLINE* and )* ;
+	and							-> not ( "and" NEWLINE* not )* ;
+	not							-> "not" not | equality ;
+	equality				-> comparison ( ( "==" | "!=" ) NEWLINE* comparison )* ;
+	comparison			-> term ( ( "<" | "<=" | ">" | ">=" ) NEWLINE* term )* ;
+	term						-> factor ( ( "+" | "-" ) NEWLINE* factor )* ;
+	factor					-> unary ( ( "*" | "/" | "%" ) NEWLINE* unary )* ;
+	unary						-> "-" unary | htmlAttrAccessor ;
 	htmlAttrAccessor-> accessor (  ( "~"? IDENT ) | expression ( "," expression )* )? ;
 	accessor 				-> ( ( primary ( ( "(" arguments? ")" ) |
 										 ( "[" expression "]" ) |
 									 		"." IDENT )* ) | mapExpr | arrayExpr | closure ) ;
-	primary					-> STRING | NUMBER | TRUE | FALSE | NIL | IDENT ;
+	primary					-> STRING | NUMBER | TRUE | FALSE | NIL | IDENT | "(" expression ")" | interpolation ;
+	interpolation		-> INTERPOLATION_START expression ( INTERPOLATION_PART expression )* INTERPOLATION_END ;
 */
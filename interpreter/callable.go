@@ -12,6 +12,9 @@ type Callable interface {
 	Arity() int
 }
 
+// Variadic is the arity of callables accepting any number of arguments
+const Variadic = -1
+
 // closure is the runtime instance of a closure definition
 type closure struct {
 	closureEnv parser.Environment
@@ -21,11 +24,18 @@ type closure struct {
 
 func (c *closure) Call(args ...interface{}) interface{} {
 	initEnv := map[string]interface{}{}
-	for i, arg := range args {
-		initEnv[c.closureAst.Params[i].Lexeme] = arg
+	for i, param := range c.closureAst.Params {
+		if i < len(args) {
+			initEnv[param.Lexeme] = args[i]
+		}
+	}
+	// A closure without parameters that is invoked with an argument e.g by `loop`, can
+	// access the argument through the implicit `it` variable
+	if len(c.closureAst.Params) == 0 && len(args) > 0 {
+		initEnv["it"] = args[0]
 	}
 	e := NewEnvironment(initEnv, c.closureEnv)
-	return c.closureAst.Body.Accept(c.i, e)
+	return c.i.execBody(c.closureAst.Body, e)
 }
 
 func (c *closure) Arity() int {
@@ -40,3 +50,39 @@ func (c *closure) String() string {
 func NewClosure(closureEnv parser.Environment, ast parser.ClosureExpr, i *Interpreter) Callable {
 	return &closure{closureEnv: closureEnv, closureAst: ast, i: i}
 }
+
+// function is a callable implemented in go
+type function struct {
+	arity int
+	fn    func(args ...interface{}) (interface{}, error)
+}
+
+// NewFunc creates a callable out of a go function, e.g to be made available to scripts with
+// Interpreter.Define. The function receives the arguments as native go values, see Sink, and
+// it's result is converted back into a runtime value. A returned error is raised as a runtime
+// error positioned at the call. The arity can be Variadic
+func NewFunc(arity int, fn func(args ...interface{}) (interface{}, error)) Callable {
+	return &function{arity: arity, fn: fn}
+}
+
+func (f *function) Call(args ...interface{}) interface{} {
+	values := make([]interface{}, len(args))
+	for index, arg := range args {
+		values[index] = native(arg)
+	}
+	val, err := f.fn(values...)
+	if err != nil {
+		panic(Error{
+			msg: err.Error(),
+		})
+	}
+	return runtimeValue(val)
+}
+
+func (f *function) Arity() int {
+	return f.arity
+}
+
+func (f *function) String() string {
+	return "#Func"
+}
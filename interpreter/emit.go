@@ -0,0 +1,216 @@
+package interpreter
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/kingzbauer/scraperlang/parser"
+)
+
+// Sink receives the records of emit statements. Records are native go values, nested maps are
+// map[string]interface{} and arrays are []interface{}. The interpreter serializes the calls to
+// Write, so a sink doesn't need to be safe for concurrent use. Exec closes the sinks once every
+// tagged closure has completed
+type Sink interface {
+	Write(record map[string]interface{}) error
+	Close() error
+}
+
+// Formats the records are written in by the sinks created with NewWriterSink and NewFileSink
+const (
+	// JSONLines writes every record as a json object on it's own line
+	JSONLines = "jsonl"
+	// CSV writes every record as a row. The header is made up of the sorted keys of the first
+	// record, keys missing from later records are left empty and keys they add are dropped
+	CSV = "csv"
+)
+
+// encoder writes records to a stream in one of the formats
+type encoder interface {
+	encode(record map[string]interface{}) error
+}
+
+type jsonLinesEncoder struct {
+	enc *json.Encoder
+}
+
+func newJSONLinesEncoder(w io.Writer) *jsonLinesEncoder {
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	return &jsonLinesEncoder{enc: enc}
+}
+
+func (e *jsonLinesEncoder) encode(record map[string]interface{}) error {
+	return e.enc.Encode(record)
+}
+
+type csvEncoder struct {
+	w      *csv.Writer
+	header []string
+}
+
+func (e *csvEncoder) encode(record map[string]interface{}) error {
+	if e.header == nil {
+		e.header = make([]string, 0, len(record))
+		for key := range record {
+			e.header = append(e.header, key)
+		}
+		sort.Strings(e.header)
+		if err := e.w.Write(e.header); err != nil {
+			return err
+		}
+	}
+	row := make([]string, len(e.header))
+	for index, key := range e.header {
+		row[index] = csvField(record[key])
+	}
+	if err := e.w.Write(row); err != nil {
+		return err
+	}
+	// Flushing every row keeps the output complete even if the sink is never closed
+	e.w.Flush()
+	return e.w.Error()
+}
+
+// csvField formats a native value as a csv field. Maps and arrays are json encoded
+func csvField(val interface{}) string {
+	switch v := val.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case map[string]interface{}, []interface{}:
+		buf := &strings.Builder{}
+		enc := json.NewEncoder(buf)
+		enc.SetEscapeHTML(false)
+		if err := enc.Encode(v); err != nil {
+			return fmt.Sprint(v)
+		}
+		return strings.TrimSuffix(buf.String(), "\n")
+	}
+	return fmt.Sprint(val)
+}
+
+// writerSink writes the records to a stream, closer is closed along with the sink if set
+type writerSink struct {
+	enc    encoder
+	closer io.Closer
+}
+
+// encoders create the encoder of every format
+var encoders = map[string]func(w io.Writer) encoder{
+	JSONLines: func(w io.Writer) encoder { return newJSONLinesEncoder(w) },
+	CSV:       func(w io.Writer) encoder { return &csvEncoder{w: csv.NewWriter(w)} },
+}
+
+func unknownFormat(format string) error {
+	return fmt.Errorf("Unknown emit format %q, expected %q or %q", format, JSONLines, CSV)
+}
+
+// CheckFormat returns an error unless the format is one the sinks write records in, either
+// JSONLines or CSV
+func CheckFormat(format string) error {
+	if _, ok := encoders[format]; !ok {
+		return unknownFormat(format)
+	}
+	return nil
+}
+
+// NewWriterSink creates a sink writing the records to w in the format, either JSONLines or CSV.
+// Closing the sink doesn't close w
+func NewWriterSink(w io.Writer, format string) (Sink, error) {
+	newEncoder, ok := encoders[format]
+	if !ok {
+		return nil, unknownFormat(format)
+	}
+	return &writerSink{enc: newEncoder(w)}, nil
+}
+
+// NewFileSink creates, or truncates, the file at path and writes the records to it in the format,
+// either JSONLines or CSV. Closing the sink closes the file
+func NewFileSink(path, format string) (Sink, error) {
+	newEncoder, ok := encoders[format]
+	if !ok {
+		return nil, unknownFormat(format)
+	}
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	return &writerSink{enc: newEncoder(file), closer: file}, nil
+}
+
+func (s *writerSink) Write(record map[string]interface{}) error {
+	return s.enc.encode(record)
+}
+
+func (s *writerSink) Close() error {
+	if s.closer == nil {
+		return nil
+	}
+	return s.closer.Close()
+}
+
+type channelSink chan<- map[string]interface{}
+
+// NewChannelSink creates a sink sending the records on the channel. Emit statements block until
+// the record is received unless the channel is buffered. Closing the sink closes the channel
+func NewChannelSink(ch chan<- map[string]interface{}) Sink {
+	return channelSink(ch)
+}
+
+func (s channelSink) Write(record map[string]interface{}) error {
+	s <- record
+	return nil
+}
+
+func (s channelSink) Close() error {
+	close(s)
+	return nil
+}
+
+// closeSinks closes every sink and returns the first error
+func (i *Interpreter) closeSinks() error {
+	i.emitMu.Lock()
+	defer i.emitMu.Unlock()
+	var err error
+	for _, sink := range i.sinks {
+		if cerr := sink.Close(); cerr != nil && err == nil {
+			err = cerr
+		}
+	}
+	return err
+}
+
+// VisitEmitExpr writes the record to every sink. Writes are serialized so the records emitted by
+// different units of work never interleave
+func (i *Interpreter) VisitEmitExpr(expr parser.EmitExpr, e parser.Environment) interface{} {
+	record, ok := expr.Record.Accept(i, e).(*Map)
+	if !ok {
+		panic(Error{
+			msg:   "emit expects a map record",
+			token: expr.Keyword,
+		})
+	}
+
+	i.emitMu.Lock()
+	defer i.emitMu.Unlock()
+	for _, sink := range i.sinks {
+		// Every sink gets it's own copy, which it's free to hold on to or modify
+		if err := sink.Write(native(record).(map[string]interface{})); err != nil {
+			panic(Error{
+				msg:   fmt.Sprintf("Could not emit the record: %s", err),
+				token: expr.Keyword,
+			})
+		}
+	}
+	return nil
+}
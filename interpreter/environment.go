@@ -10,6 +10,9 @@ import (
 type environment struct {
 	entries map[string]interface{}
 	parent  parser.Environment
+	// depth is the number of tagged closure dispatches that led to this environment. It's
+	// inherited from the parent environment
+	depth int
 }
 
 // NewEnvironment returns a new Environment implementation with an initial set of values
@@ -18,10 +21,31 @@ func NewEnvironment(init map[string]interface{}, parent parser.Environment) pars
 	for key, value := range init {
 		e.entries[key] = value
 	}
+	if p, ok := parent.(*environment); ok {
+		e.depth = p.depth
+	}
 	return e
 
 }
 
+// newTaggedEnvironment returns the environment of a tagged closure executing at the provided
+// crawl depth. The depth is accessible to the script through the `depth` variable. It's parent is
+// the global environment holding the values defined by the host program
+func (i *Interpreter) newTaggedEnvironment(init map[string]interface{}, depth int) parser.Environment {
+	e := NewEnvironment(init, i.globals).(*environment)
+	e.depth = depth
+	e.entries["depth"] = float64(depth)
+	return e
+}
+
+// depthOf returns the crawl depth of the environment
+func depthOf(e parser.Environment) int {
+	if env, ok := e.(*environment); ok {
+		return env.depth
+	}
+	return 0
+}
+
 // Get checks and returns the given variable from either itself or the parent.
 // If both don't get the variable, it panics with undefined variable
 func (e *environment) Get(ident token.Token) interface{} {
@@ -1,6 +1,10 @@
 package interpreter
 
 import (
+	"bytes"
+	"fmt"
+	"strings"
+
 	"github.com/PuerkitoBio/goquery"
 	"golang.org/x/net/html"
 )
@@ -8,11 +12,12 @@ import (
 // Selector is the main interface implemented when quering into a document e.g HTML document
 type Selector interface {
 	Accessor
+	Find(selector string) *Array
 }
 
 // Noder is a single html node from which we can access node attributes
 type Noder interface {
-	GetAttribute(key string) string
+	GetAttribute(key string) (string, bool)
 }
 
 // Selection implements the selector interface
@@ -20,7 +25,141 @@ type Selection struct {
 	document *goquery.Document
 }
 
+// NewSelection parses the provided HTML content and returns a selection of the whole document
+func NewSelection(content []byte) (*Selection, error) {
+	doc, err := goquery.NewDocumentFromReader(bytes.NewReader(content))
+	if err != nil {
+		return nil, err
+	}
+	return &Selection{document: doc}, nil
+}
+
+// Find returns an array of all the nodes in the document that match the css selector
+func (s *Selection) Find(selector string) *Array {
+	return findNodes(s.document.Selection, selector)
+}
+
+// Get implements the Accessor interface for a selection.
+//
+//	1. `jq` a callable that queries the document with a css selector
+//	2. `text` the combined text contents of the document
+//	3. `html` the html contents of the document
+func (s *Selection) Get(attr string) interface{} {
+	switch attr {
+	case "jq":
+		return &query{selector: s}
+	case "text":
+		return s.document.Text()
+	case "html":
+		content, _ := goquery.OuterHtml(s.document.Selection)
+		return content
+	default:
+		panic(Error{
+			msg: fmt.Sprintf("Selection does not have an attribute %q", attr),
+		})
+	}
+}
+
+func (s *Selection) String() string {
+	content, _ := goquery.OuterHtml(s.document.Selection)
+	return content
+}
+
 // Node represents a single HTML node and implements the Noder interface
 type Node struct {
 	node *html.Node
 }
+
+// GetAttribute returns the value of the attribute key and whether the node has the attribute
+func (n *Node) GetAttribute(key string) (string, bool) {
+	for _, attr := range n.node.Attr {
+		if attr.Key == key {
+			return attr.Val, true
+		}
+	}
+	return "", false
+}
+
+// Find returns an array of all the descendant nodes that match the css selector
+func (n *Node) Find(selector string) *Array {
+	return findNodes(n.selection(), selector)
+}
+
+// Get implements the Accessor interface for a node.
+//
+//	1. `jq` a callable that queries the node's descendants with a css selector
+//	2. `text` the combined text contents of the node and it's descendants
+//	3. `html` the outer html of the node
+//	4. `tag` the name of the html tag e.g `a`
+func (n *Node) Get(attr string) interface{} {
+	switch attr {
+	case "jq":
+		return &query{selector: n}
+	case "text":
+		return n.selection().Text()
+	case "html":
+		content, _ := goquery.OuterHtml(n.selection())
+		return content
+	case "tag":
+		return n.node.Data
+	default:
+		panic(Error{
+			msg: fmt.Sprintf("Node does not have an attribute %q", attr),
+		})
+	}
+}
+
+func (n *Node) selection() *goquery.Selection {
+	return goquery.NewDocumentFromNode(n.node).Selection
+}
+
+func (n *Node) String() string {
+	buf := &strings.Builder{}
+	buf.WriteString("<" + n.node.Data)
+	for _, attr := range n.node.Attr {
+		buf.WriteString(fmt.Sprintf(" %s=%q", attr.Key, attr.Val))
+	}
+	buf.WriteString(">")
+	return buf.String()
+}
+
+// attributeValue returns the attribute value of the node or nil if the attribute is missing
+func attributeValue(n Noder, key string) interface{} {
+	if val, ok := n.GetAttribute(key); ok {
+		return val
+	}
+	return nil
+}
+
+func findNodes(sel *goquery.Selection, selector string) *Array {
+	found := sel.Find(selector)
+	a := &Array{entries: make([]interface{}, len(found.Nodes))}
+	for index, node := range found.Nodes {
+		a.entries[index] = &Node{node: node}
+	}
+	return a
+}
+
+// query is the `jq` builtin. It runs a css selector against it's selector and returns
+// an array of the matching nodes
+type query struct {
+	selector Selector
+}
+
+func (q *query) Call(args ...interface{}) interface{} {
+	selector, ok := args[0].(string)
+	if !ok {
+		panic(Error{
+			msg: "'jq' expects a css selector string as it's only argument",
+		})
+	}
+	return q.selector.Find(selector)
+}
+
+func (q *query) Arity() int {
+	return 1
+}
+
+func (q *query) String() string {
+	return "#Builtin jq"
+}
@@ -3,10 +3,16 @@ package interpreter
 import (
 	"errors"
 	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"strings"
 	"sync"
 
 	"github.com/panjf2000/ants/v2"
 
+	"github.com/kingzbauer/scraperlang/diag"
 	"github.com/kingzbauer/scraperlang/parser"
 	"github.com/kingzbauer/scraperlang/token"
 )
@@ -23,23 +29,94 @@ type Error struct {
 }
 
 func (err Error) Error() string {
-	prefix := ""
-	if err.token != nil {
-		prefix = fmt.Sprintf("[%d:%d]", err.token.Line+1, err.token.Column)
+	return err.Diagnostic().Error()
+}
+
+// Diagnostic implements the diag.Diagnoser interface
+func (err Error) Diagnostic() diag.Diagnostic {
+	return err.token.Diagnostic(diag.Error, err.msg)
+}
+
+// locate attaches the position of the token to runtime errors raised without one, e.g by the
+// runtime maps and arrays which have no knowledge of the source. It's meant to be deferred
+func locate(t *token.Token) {
+	if val := recover(); val != nil {
+		if err, ok := val.(Error); ok && err.token == nil {
+			err.token = t
+			panic(err)
+		}
+		panic(val)
 	}
-	return fmt.Sprintf("%s %s", prefix, err.msg)
 }
 
 // Interpreter implements the Visitor interface and the Eval loop
 type Interpreter struct {
 	ast            []parser.Expr
 	taggedClosures map[string]parser.TaggedClosure
-	wg             *sync.WaitGroup
-	pool           *ants.Pool
+	// maxDepth is the crawl depth limit of each tagged closure
+	maxDepth map[string]int
+	wg       *sync.WaitGroup
+	pool     *ants.Pool
+	queue    *workQueue
+	// mu guards errs which is written to by the units of work running in the pool
+	mu   sync.Mutex
+	errs []error
+	// emitMu serializes the writes of the records of emit statements to the sinks
+	emitMu sync.Mutex
+	sinks  []Sink
+	client *http.Client
+	// outputMu serializes the writes of print statements to output
+	outputMu sync.Mutex
+	output   io.Writer
+	onError  func(error)
+	logger   *log.Logger
+	// globals is the parent of the environment of every tagged closure, it holds the values
+	// defined by the host program
+	globals parser.Environment
+}
+
+// Err returns the runtime errors raised by the tagged closures invoked from the pool and the
+// requests that could not be completed
+func (i *Interpreter) Err() []error {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	return append([]error(nil), i.errs...)
+}
+
+// HasErrs checks to see whether any of the units of work failed
+func (i *Interpreter) HasErrs() bool {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	return len(i.errs) > 0
+}
+
+func (i *Interpreter) addErr(err error) {
+	i.mu.Lock()
+	i.errs = append(i.errs, err)
+	i.mu.Unlock()
+	if i.onError != nil {
+		i.onError(err)
+	}
+}
+
+// logf logs the message if a logger is set
+func (i *Interpreter) logf(format string, args ...interface{}) {
+	if i.logger != nil {
+		i.logger.Printf(format, args...)
+	}
 }
 
 // VisitBodyExpr executes all the expressions in the body expressions
-func (i *Interpreter) VisitBodyExpr(expr parser.BodyExpr, e parser.Environment) (val interface{}) {
+func (i *Interpreter) VisitBodyExpr(expr parser.BodyExpr, e parser.Environment) interface{} {
+	for _, exp := range expr.Exprs {
+		exp.Accept(i, e)
+	}
+	return nil
+}
+
+// execBody executes the body of a closure or tagged closure and returns the value of the return
+// statement that ended it's execution if any
+func (i *Interpreter) execBody(body parser.Expr, e parser.Environment) (val interface{}) {
 	defer func() {
 		if v := recover(); v != nil {
 			if returnExp, ok := v.(ReturnException); ok {
@@ -50,9 +127,56 @@ func (i *Interpreter) VisitBodyExpr(expr parser.BodyExpr, e parser.Environment)
 		}
 	}()
 
-	for _, exp := range expr.Exprs {
-		exp.Accept(i, e)
+	return body.Accept(i, e)
+}
+
+// VisitIfExpr executes the branch selected by the truthiness of the condition. The branches share the
+// environment of the enclosing body
+func (i *Interpreter) VisitIfExpr(expr parser.IfExpr, e parser.Environment) interface{} {
+	if truthy(expr.Condition.Accept(i, e)) {
+		expr.Then.Accept(i, e)
+	} else if expr.Else != nil {
+		expr.Else.Accept(i, e)
 	}
+	return nil
+}
+
+// VisitTryExpr executes the body and, if it raises a runtime error, the catch body with the error
+// variable set to a map of the error message, line and column. Both bodies share the environment
+// of the enclosing body. Errors raised by the tagged closures invoked from the body aren't caught
+// as they are executed asynchronously
+func (i *Interpreter) VisitTryExpr(expr parser.TryExpr, e parser.Environment) interface{} {
+	err, ok := i.try(expr.Body, e)
+	if !ok {
+		return nil
+	}
+	diagnostic := err.Diagnostic()
+	var line, column interface{}
+	if diagnostic.HasPosition() {
+		line, column = float64(diagnostic.Start.Line+1), float64(diagnostic.Start.Column+1)
+	}
+	e.Set(*expr.Name, &Map{instance: map[string]interface{}{
+		"message": diagnostic.Msg,
+		"line":    line,
+		"column":  column,
+	}})
+	expr.Handler.Accept(i, e)
+	return nil
+}
+
+// try executes the body and returns the runtime error it raised if any. Return, break and continue
+// statements unwind through it
+func (i *Interpreter) try(body parser.Expr, e parser.Environment) (err Error, raised bool) {
+	defer func() {
+		if val := recover(); val != nil {
+			if runtimeErr, ok := val.(Error); ok {
+				err, raised = runtimeErr, true
+				return
+			}
+			panic(val)
+		}
+	}()
+	body.Accept(i, e)
 	return
 }
 
@@ -67,12 +191,52 @@ func (i *Interpreter) VisitReturnExpr(expr parser.ReturnExpr, e parser.Environme
 	})
 }
 
-// New creates a new Intepreter instance
-func New(ast []parser.Expr) (*Interpreter, error) {
-	i := &Interpreter{}
+// New creates a new Intepreter instance configured by the options
+func New(ast []parser.Expr, opts ...Option) (*Interpreter, error) {
+	var options Options
+	for _, opt := range opts {
+		opt(&options)
+	}
+	return NewWithOptions(ast, options)
+}
+
+// NewWithOptions creates a new Interpreter instance configured by the options
+func NewWithOptions(ast []parser.Expr, opts Options) (*Interpreter, error) {
+	i := &Interpreter{
+		client:  opts.Client,
+		output:  opts.Output,
+		onError: opts.OnError,
+		logger:  opts.Logger,
+		sinks:   append([]Sink(nil), opts.Sinks...),
+		globals: NewEnvironment(nil, nil),
+	}
+	for name, value := range opts.Builtins {
+		i.Define(name, value)
+	}
+	if i.client == nil {
+		i.client = http.DefaultClient
+	}
+	if i.output == nil {
+		i.output = os.Stdout
+	}
+	formats := opts.OutputFormats
+	if len(i.sinks) == 0 && len(formats) == 0 {
+		formats = []string{JSONLines}
+	}
+	for _, format := range formats {
+		sink, err := NewWriterSink(&outputWriter{i}, format)
+		if err != nil {
+			return nil, err
+		}
+		i.sinks = append(i.sinks, sink)
+	}
+	poolSize := opts.PoolSize
+	if poolSize <= 0 {
+		poolSize = defaultPoolSize
+	}
 	i.taggedClosures = make(map[string]parser.TaggedClosure)
-	// 1. We expect the top level expression to be tagged closures
-	// 2. There is 1 required tagged closure: 'init'
+	// We expect the top level expression to be tagged closures. The required 'init' tagged closure
+	// is checked by Exec, which allows an interpreter without any to evaluate statements with Eval
 	for _, expr := range ast {
 		if closure, ok := expr.(parser.TaggedClosure); ok {
 			i.taggedClosures[closure.Name.Lexeme] = closure
@@ -81,27 +245,50 @@ func New(ast []parser.Expr) (*Interpreter, error) {
 		}
 	}
 
-	// Assert that we have the init closure
-	if _, ok := i.taggedClosures["init"]; !ok {
-		return nil, errors.New("Missing 'init' tagged closure")
+	i.maxDepth = make(map[string]int)
+	for name, closure := range i.taggedClosures {
+		for _, option := range closure.Options {
+			switch option.Name.Lexeme {
+			case "max_depth":
+				var depth float64
+				literal, ok := option.Value.(parser.LiteralExpr)
+				if ok {
+					depth, ok = literal.Value.Literal.(float64)
+				}
+				if !ok || depth < 0 {
+					return nil, Error{
+						msg:   "'max_depth' expects a non-negative number",
+						token: option.Name,
+					}
+				}
+				i.maxDepth[name] = int(depth)
+			default:
+				return nil, Error{
+					msg:   fmt.Sprintf("Unknown tagged closure option %q", option.Name.Lexeme),
+					token: option.Name,
+				}
+			}
+		}
 	}
 
 	i.wg = &sync.WaitGroup{}
 	var err error
-	if i.pool, err = ants.NewPool(10, ants.WithPanicHandler(func(val interface{}) {
-		if err, ok := val.(Error); ok {
-			fmt.Print(err)
-		} else {
-			panic(val)
-		}
-	})); err != nil {
+	if i.pool, err = ants.NewPool(poolSize); err != nil {
 		return nil, err
 	}
+	i.queue = newWorkQueue(func(work func()) {
+		if err := i.pool.Submit(work); err != nil {
+			i.wg.Done()
+			i.addErr(Error{msg: err.Error()})
+		}
+	})
 
 	return i, nil
 }
 
-// Exec starts the execution flow for the interpreter
+// Exec starts the execution flow for the interpreter. It returns the runtime error raised by the
+// 'init' tagged closure or the error closing the sinks, errors raised by the rest of the tagged
+// closures are retrieved with Err once it returns
 func (i *Interpreter) Exec() (err error) {
 	defer func() {
 		if val := recover(); val != nil {
@@ -111,80 +298,238 @@ func (i *Interpreter) Exec() (err error) {
 				err = fmt.Errorf("%v", val)
 			}
 		}
+		// The sinks are only closed once nothing can emit to them anymore
+		i.wg.Wait()
+		if cerr := i.closeSinks(); err == nil {
+			err = cerr
+		}
+		i.Close()
 	}()
 
-	e := NewEnvironment(nil, nil)
+	// Assert that we have the init closure
+	closure, ok := i.taggedClosures["init"]
+	if !ok {
+		return ErrMissingInit
+	}
+
+	e := i.newTaggedEnvironment(nil, 0)
 	// we start our execution from the init closure
-	i.taggedClosures["init"].Accept(i, e)
+	closure.Accept(i, e)
+
+	return
+}
 
-	// Wait for all closures to finish before exiting
+// Close waits for the pending units of work and releases the workers of the pool. Exec closes the
+// interpreter once the script completes, an interpreter only used with Eval is closed by the host
+// program. Requests made after Close are recorded as errors
+func (i *Interpreter) Close() {
 	i.wg.Wait()
+	i.pool.Release()
+}
+
+// Define makes the value available to scripts as a global variable. Callables, e.g created with
+// NewFunc, can be called by the scripts while native go slices, arrays, maps with string keys and
+// numbers of any kind are converted into their runtime representation. The variable can be shadowed by the scripts, but not modified.
+// Define isn't safe to call while the interpreter is executing
+func (i *Interpreter) Define(name string, value interface{}) {
+	i.globals.Set(token.Token{Type: token.Ident, Lexeme: name}, runtimeValue(value))
+}
+
+// Globals returns the environment holding the values defined by the host program. It's meant to
+// be the parent of the environments passed to Eval
+func (i *Interpreter) Globals() parser.Environment {
+	return i.globals
+}
+
+// Eval executes the statements in the environment, as if they were the body of a tagged closure,
+// and returns the value of the last one. It waits for the requests made by the statements to
+// complete, their errors are retrieved with Err.
+func (i *Interpreter) Eval(stmts []parser.Expr, e parser.Environment) (val interface{}, err error) {
+	defer func() {
+		if v := recover(); v != nil {
+			switch ex := v.(type) {
+			case ReturnException:
+				val = ex.Value
+			case BreakException, ContinueException:
+				err = errors.New("break and continue are only allowed within a loop")
+			case error:
+				err = ex
+			default:
+				panic(v)
+			}
+		}
+		i.wg.Wait()
+	}()
+
+	for _, stmt := range stmts {
+		val = stmt.Accept(i, e)
+	}
 	return
 }
 
+// Fetch makes a get request to the url and sets the variables a tagged closure receives for the
+// response in the environment: response, status, headers, content and jq
+func (i *Interpreter) Fetch(url string, e parser.Environment) error {
+	vars, err := i.fetch(requestWorkConfig{method: http.MethodGet, url: url})
+	if err != nil {
+		return err
+	}
+	for name, value := range vars {
+		e.Set(token.Token{Type: token.Ident, Lexeme: name}, value)
+	}
+	return nil
+}
+
 // VisitTaggedClosure visits the tagged closure expression
 func (i *Interpreter) VisitTaggedClosure(expr parser.TaggedClosure, e parser.Environment) interface{} {
-	expr.Body.Accept(i, e)
+	i.execBody(expr.Body, e)
 	return nil
 }
 
 // VisitGetExpr given a get expression, executes the requested http call and calls
-// the specified tagged closure
+// the specified tagged closure. When the URL evaluates to an array, a request is made for
+// every entry of the array
 func (i *Interpreter) VisitGetExpr(expr parser.GetExpr, e parser.Environment) interface{} {
-	var (
-		url string
-		ok  bool
-	)
-	val := expr.URL.Accept(i, e)
-	if url, ok = val.(string); !ok {
+	i.dispatch(http.MethodGet, expr.Method, expr.Tag, expr.URL, nil, expr.Header, e)
+	return nil
+}
+
+// VisitRequestExpr given a request expression e.g post, executes the requested http call with the
+// provided body and calls the specified tagged closure
+func (i *Interpreter) VisitRequestExpr(expr parser.RequestExpr, e parser.Environment) interface{} {
+	i.dispatch(strings.ToUpper(expr.Method.Lexeme), expr.Method, expr.Tag, expr.URL, expr.Body, expr.Header, e)
+	return nil
+}
+
+// dispatch evaluates the arguments of a request expression and submits a unit of work for every
+// requested URL
+func (i *Interpreter) dispatch(method string, keyword, tag *token.Token, URL, body, header parser.Expr, e parser.Environment) {
+	defer locate(keyword)
+
+	var urls []string
+	switch val := URL.Accept(i, e).(type) {
+	case string:
+		urls = []string{val}
+	case *Array:
+		urls = make([]string, len(val.entries))
+		for index, entry := range val.entries {
+			url, ok := entry.(string)
+			if !ok {
+				panic(Error{
+					msg:   fmt.Sprintf("'%s' expects an array of URL strings, got %v at index %d", keyword.Lexeme, entry, index),
+					token: keyword,
+				})
+			}
+			urls[index] = url
+		}
+	default:
 		panic(Error{
-			msg: "'get' expects a URL string as it's 1st argument",
+			msg:   fmt.Sprintf("'%s' expects a URL string or an array of URL strings as it's 1st argument", keyword.Lexeme),
+			token: keyword,
 		})
 	}
 
-	var headers map[string]interface{}
-	if expr.Header != nil {
-		val := expr.Header.Accept(i, e)
+	var headers http.Header
+	if header != nil {
+		val := header.Accept(i, e)
 		mapVal, ok := val.(*Map)
 		if !ok {
+			position := "2nd"
+			if body != nil {
+				position = "3rd"
+			}
 			panic(Error{
-				msg: fmt.Sprintf("'get', requires a map as it's 2nd argument"),
+				msg:   fmt.Sprintf("'%s', requires a map as it's %s argument", keyword.Lexeme, position),
+				token: keyword,
 			})
 		}
-		headers = mapVal.instance
+		headers = requestHeaders(mapVal)
 	}
-	cfg := getWorkConfig{
+
+	cfg := requestWorkConfig{
+		method:  method,
+		keyword: keyword,
 		// We will use default as the, well, 'default' tag
 		tag:     "default",
-		url:     url,
 		headers: headers,
+		depth:   depthOf(e) + 1,
 	}
-	if expr.Tag != nil {
-		cfg.tag = expr.Tag.Literal.(string)
+	if tag != nil {
+		cfg.tag = tag.Literal.(string)
+	}
+	// Requests that would exceed the crawl depth of the tagged closure are dropped
+	if limit, ok := i.depthLimit(cfg.tag); ok && cfg.depth > limit {
+		for _, url := range urls {
+			i.logf("dropping %s %s, depth %d exceeds the max_depth %d of %q", method, url, cfg.depth, limit, cfg.tag)
+		}
+		return
+	}
+	if body != nil {
+		cfg.body, cfg.contentType = encodeBody(keyword.Lexeme, body.Accept(i, e), headers)
 	}
 
-	work := i.newGetWork(cfg)
-	i.wg.Add(1)
-	if err := i.pool.Submit(work); err != nil {
-		panic(Error{
-			msg: err.Error(),
-		})
+	for _, url := range urls {
+		cfg.url = url
+		i.submit(i.newRequestWork(cfg))
 	}
+}
 
-	return nil
+// depthLimit returns the maximum crawl depth of the tagged closure. Tagged closures without a
+// 'max_depth' option fallback to the one defined on 'init' if any
+func (i *Interpreter) depthLimit(tag string) (int, bool) {
+	if limit, ok := i.maxDepth[tag]; ok {
+		return limit, true
+	}
+	limit, ok := i.maxDepth["init"]
+	return limit, ok
+}
+
+// submit queues the unit of work for the pool without blocking, it's called from the units of
+// work running in the pool. The unit of work is responsible for calling wg.Done once it completes
+func (i *Interpreter) submit(work func()) {
+	i.wg.Add(1)
+	i.queue.push(work)
 }
 
-// VisitPrintExpr prints the provided arguments to stdout
+// VisitPrintExpr prints the provided arguments to the output, stdout by default
 func (i *Interpreter) VisitPrintExpr(expr parser.PrintExpr, e parser.Environment) interface{} {
 	values := make([]interface{}, len(expr.Args))
 	for index, expr := range expr.Args {
 		values[index] = expr.Accept(i, e)
 	}
 
-	fmt.Println(values...)
+	i.outputMu.Lock()
+	defer i.outputMu.Unlock()
+	fmt.Fprintln(i.output, values...)
 	return nil
 }
 
+// outputWriter writes to the output of the interpreter, serialized with the print statements
+type outputWriter struct {
+	i *Interpreter
+}
+
+func (w *outputWriter) Write(p []byte) (int, error) {
+	w.i.outputMu.Lock()
+	defer w.i.outputMu.Unlock()
+	return w.i.output.Write(p)
+}
+
+// VisitInterpolationExpr builds a string from the string segments and the values of the embedded
+// expressions, which are formatted the same way print formats them
+func (i *Interpreter) VisitInterpolationExpr(expr parser.InterpolationExpr, e parser.Environment) interface{} {
+	buf := &strings.Builder{}
+	for _, part := range expr.Parts {
+		buf.WriteString(stringify(part.Accept(i, e)))
+	}
+	return buf.String()
+}
+
+// stringify formats a runtime value the same way print does
+func stringify(val interface{}) string {
+	return fmt.Sprint(val)
+}
+
 // VisitAssignExpr creates a new variable with the value as the expression value
 func (i *Interpreter) VisitAssignExpr(expr parser.AssignExpr, e parser.Environment) interface{} {
 	val := expr.Value.Accept(i, e)
@@ -194,16 +539,25 @@ func (i *Interpreter) VisitAssignExpr(expr parser.AssignExpr, e parser.Environme
 
 // VisitCallExpr executes the callable with the given arguments
 func (i *Interpreter) VisitCallExpr(expr parser.CallExpr, e parser.Environment) interface{} {
+	// Free form calls don't have a parenthesis, they are located by the callee instead
+	t := expr.Paren
+	if t == nil {
+		t = parser.FirstToken(expr.Name)
+	}
+	defer locate(t)
+
 	val := expr.Name.Accept(i, e)
 	callable, ok := val.(Callable)
 	if !ok {
 		panic(Error{
-			msg: fmt.Sprintf("%q is not a callable", val),
+			msg:   fmt.Sprintf("%v is not a callable", val),
+			token: t,
 		})
 	}
-	if callable.Arity() != len(expr.Arguments) {
+	if callable.Arity() != Variadic && callable.Arity() != len(expr.Arguments) {
 		panic(Error{
-			msg: fmt.Sprintf("Expect %d arguments, got %d", callable.Arity(), len(expr.Arguments)),
+			msg:   fmt.Sprintf("Expect %d arguments, got %d", callable.Arity(), len(expr.Arguments)),
+			token: t,
 		})
 	}
 
@@ -224,17 +578,48 @@ func (i *Interpreter) VisitClosureExpr(expr parser.ClosureExpr, e parser.Environ
 // VisitAccessExpr allows the retrieving of attributes from a runtime instance that implements the Accessor
 // interface
 func (i *Interpreter) VisitAccessExpr(expr parser.AccessExpr, e parser.Environment) interface{} {
+	defer locate(expr.Field)
+
 	val := expr.Var.Accept(i, e)
 	if accessor, ok := val.(Accessor); ok {
 		return accessor.Get(expr.Field.Lexeme)
 	}
 	panic(Error{
-		msg: fmt.Sprintf("%s does not implement the Accessor interface", val),
+		msg:   fmt.Sprintf("%v does not implement the Accessor interface", val),
+		token: expr.Field,
 	})
 }
 
-func (i *Interpreter) VisitHTMLAttrAccessor(_ parser.HTMLAttrAccessor, _ parser.Environment) interface{} {
-	panic("not implemented") // TODO: Implement
+// VisitHTMLAttrAccessor retrieves an html attribute from a node. When applied to an array of nodes,
+// it returns an array with the attribute value of each node. Missing attributes evaluate to nil
+func (i *Interpreter) VisitHTMLAttrAccessor(expr parser.HTMLAttrAccessor, e parser.Environment) interface{} {
+	val := expr.Var.Accept(i, e)
+	switch v := val.(type) {
+	case Noder:
+		return attributeValue(v, expr.Attr.Lexeme)
+	case *Selection:
+		panic(Error{
+			msg:   fmt.Sprintf("'~%s' can't be applied to the whole document, select the nodes with 'jq' first", expr.Attr.Lexeme),
+			token: expr.Attr,
+		})
+	case *Array:
+		a := &Array{entries: make([]interface{}, len(v.entries))}
+		for index, entry := range v.entries {
+			node, ok := entry.(Noder)
+			if !ok {
+				panic(Error{
+					msg:   fmt.Sprintf("'~%s' expects an array of html nodes, got %v at index %d", expr.Attr.Lexeme, entry, index),
+					token: expr.Attr,
+				})
+			}
+			a.entries[index] = attributeValue(node, expr.Attr.Lexeme)
+		}
+		return a
+	}
+	panic(Error{
+		msg:   fmt.Sprintf("'~%s' expects an html node or an array of html nodes, got %v", expr.Attr.Lexeme, val),
+		token: expr.Attr,
+	})
 }
 
 // VisitArrayExpr creates a runtime list
@@ -260,7 +645,8 @@ func (i *Interpreter) VisitMapExpr(expr parser.MapExpr, e parser.Environment) in
 // VisitLiteralExpr returns the underlying literal value
 func (i *Interpreter) VisitLiteralExpr(expr parser.LiteralExpr, e parser.Environment) interface{} {
 	switch expr.Value.Type {
-	case token.String, token.Number, token.Nil, token.True, token.False:
+	case token.String, token.Number, token.Nil, token.True, token.False,
+		token.InterpolationStart, token.InterpolationPart, token.InterpolationEnd:
 		return expr.Value.Literal
 	case token.Ident:
 		return e.Get(*expr.Value)
@@ -275,13 +661,15 @@ func (i *Interpreter) VisitIdentExpr(expr parser.IdentExpr, e parser.Environment
 
 // VisitMapAccessExpr indexes into either a list or hash map
 func (i *Interpreter) VisitMapAccessExpr(expr parser.MapAccessExpr, e parser.Environment) interface{} {
+	defer locate(expr.Bracket)
+
 	val := expr.Name.Accept(i, e)
 	// The value needs to implement the Keyer interface
 	if keyer, ok := val.(Keyer); ok {
 		return keyer.GetValue(expr.Key.Accept(i, e))
 	}
 	panic(Error{
-		msg: fmt.Sprintf("%s cannot be indexed", val),
+		msg:   fmt.Sprintf("%v cannot be indexed", val),
+		token: expr.Bracket,
 	})
-
 }
@@ -0,0 +1,138 @@
+package interpreter
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/kingzbauer/scraperlang/parser"
+	"github.com/kingzbauer/scraperlang/token"
+)
+
+// newTestInterpreter parses the source and creates an interpreter writing it's output to out
+func newTestInterpreter(t *testing.T, src string, out *bytes.Buffer, opts ...Option) *Interpreter {
+	t.Helper()
+	tokens, err := token.NewScanner([]byte(src)).ScanTokens()
+	if err != nil {
+		t.Fatalf("scanning the source: %s", err)
+	}
+	p := parser.New(tokens)
+	ast, err := p.Parse()
+	if err == nil && p.HasErrs() {
+		err = p.Err()[0]
+	}
+	if err != nil {
+		t.Fatalf("parsing the source: %s", err)
+	}
+	i, err := New(ast, append([]Option{WithOutput(out)}, opts...)...)
+	if err != nil {
+		t.Fatalf("creating the interpreter: %s", err)
+	}
+	return i
+}
+
+func TestExecRecordsErrorsOfTaggedClosures(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("<html></html>"))
+	}))
+	defer server.Close()
+
+	src := `init {
+  @page get url + "/a"
+  @page get url + "/b"
+}
+
+page {
+  x = 1 + nil
+}
+`
+	// The errors are recorded before Exec returns, however quickly the units of work complete
+	for run := 0; run < 50; run++ {
+		out := &bytes.Buffer{}
+		i := newTestInterpreter(t, src, out, WithHTTPClient(server.Client()), WithBuiltins(map[string]interface{}{
+			"url": server.URL,
+		}))
+		if err := i.Exec(); err != nil {
+			t.Fatalf("executing the script: %s", err)
+		}
+		if errs := i.Err(); len(errs) != 2 {
+			t.Fatalf("got %d errors, want 2: %v", len(errs), errs)
+		}
+		for _, err := range i.Err() {
+			if !strings.Contains(err.Error(), "'+' expects number operands") {
+				t.Errorf("unexpected error %q", err)
+			}
+		}
+	}
+}
+
+func TestDefineConvertsNativeValues(t *testing.T) {
+	type label string
+	src := `init {
+  total = 0
+  for id in ids {
+    total = total + id
+  }
+  print total, ids[1], scores['b'] + 1, small + big, ratios[0] * 2
+  print nested['a'][1]['n'], name + '!', flags[0]
+  print add(ids[0], 2)
+}
+`
+	out := &bytes.Buffer{}
+	i := newTestInterpreter(t, src, out, WithBuiltins(map[string]interface{}{
+		"ids":    []int{1, 2},
+		"scores": map[string]int{"a": 1, "b": 2},
+		"small":  int32(3),
+		"big":    uint(4),
+		"ratios": [2]float64{0.25, 0.5},
+		"nested": map[string][]map[string]uint8{"a": {{"n": 1}, {"n": 7}}},
+		"name":   label("x"),
+		"flags":  []bool{true},
+		"add": NewFunc(2, func(args ...interface{}) (interface{}, error) {
+			return []int64{int64(args[0].(float64) + args[1].(float64))}, nil
+		}),
+	}))
+	if err := i.Exec(); err != nil {
+		t.Fatalf("executing the script: %s", err)
+	}
+	if errs := i.Err(); len(errs) > 0 {
+		t.Fatalf("unexpected runtime errors %v", errs)
+	}
+	want := "3 2 3 7 0.5\n7 x! true\n#Array [3]\n"
+	if out.String() != want {
+		t.Errorf("got output %q, want %q", out.String(), want)
+	}
+}
+
+func TestOutputSinks(t *testing.T) {
+	src := `init {
+  print 'start'
+  emit {'a': 1, 'b': 'x'}
+  print 'end'
+}
+`
+	tests := []struct {
+		name string
+		opts []Option
+		want string
+	}{
+		{"default", nil, "start\n{\"a\":1,\"b\":\"x\"}\nend\n"},
+		{"csv", []Option{WithOutputSinks(CSV)}, "start\na,b\n1,x\nend\n"},
+		{"other sinks", []Option{WithSinks(NewChannelSink(make(chan map[string]interface{}, 1)))}, "start\nend\n"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			out := &bytes.Buffer{}
+			i := newTestInterpreter(t, src, out, test.opts...)
+			if err := i.Exec(); err != nil {
+				t.Fatalf("executing the script: %s", err)
+			}
+			if out.String() != test.want {
+				t.Errorf("got output %q, want %q", out.String(), test.want)
+			}
+		})
+	}
+}
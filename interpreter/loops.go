@@ -0,0 +1,97 @@
+package interpreter
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/kingzbauer/scraperlang/parser"
+)
+
+// BreakException is used to exit the innermost loop by way of panic
+type BreakException struct{}
+
+// ContinueException is used to skip to the next iteration of the innermost loop by way of panic
+type ContinueException struct{}
+
+// VisitForExpr executes the body for every entry of an array or map. With a single loop variable, it
+// holds the entry value. With two, the first holds the array index or map key. Maps are iterated in
+// the sorted order of their keys
+func (i *Interpreter) VisitForExpr(expr parser.ForExpr, e parser.Environment) interface{} {
+	iterate := func(key, value interface{}) bool {
+		if expr.Key != nil {
+			e.Set(*expr.Key, key)
+		}
+		e.Set(*expr.Value, value)
+		return i.execLoopBody(expr.Body, e)
+	}
+
+	switch iterable := expr.Iterable.Accept(i, e).(type) {
+	case *Array:
+		for index, entry := range iterable.entries {
+			if !iterate(float64(index), entry) {
+				break
+			}
+		}
+	case *Map:
+		for _, key := range iterable.keys() {
+			if !iterate(key, iterable.instance[key]) {
+				break
+			}
+		}
+	default:
+		panic(Error{
+			msg:   fmt.Sprintf("'for' expects an array or map to iterate over, got %v", iterable),
+			token: expr.Keyword,
+		})
+	}
+	return nil
+}
+
+// VisitWhileExpr executes the body for as long as the condition is true
+func (i *Interpreter) VisitWhileExpr(expr parser.WhileExpr, e parser.Environment) interface{} {
+	for truthy(expr.Condition.Accept(i, e)) {
+		if !i.execLoopBody(expr.Body, e) {
+			break
+		}
+	}
+	return nil
+}
+
+// VisitBreakExpr exits the innermost loop
+func (i *Interpreter) VisitBreakExpr(_ parser.BreakExpr, _ parser.Environment) interface{} {
+	panic(BreakException{})
+}
+
+// VisitContinueExpr skips to the next iteration of the innermost loop
+func (i *Interpreter) VisitContinueExpr(_ parser.ContinueExpr, _ parser.Environment) interface{} {
+	panic(ContinueException{})
+}
+
+// execLoopBody executes a single iteration of a loop and returns whether the loop should continue
+func (i *Interpreter) execLoopBody(body parser.Expr, e parser.Environment) (next bool) {
+	defer func() {
+		if v := recover(); v != nil {
+			switch v.(type) {
+			case BreakException:
+				next = false
+			case ContinueException:
+				next = true
+			default:
+				panic(v)
+			}
+		}
+	}()
+
+	body.Accept(i, e)
+	return true
+}
+
+// keys returns the map keys in sorted order
+func (m *Map) keys() []string {
+	keys := make([]string, 0, len(m.instance))
+	for key := range m.instance {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
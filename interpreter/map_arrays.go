@@ -1,6 +1,9 @@
 package interpreter
 
-import "fmt"
+import (
+	"fmt"
+	"reflect"
+)
 
 // Map is a runtime hash map implementation
 type Map struct {
@@ -12,8 +15,9 @@ func (m *Map) Call(args ...interface{}) interface{} {
 	fun := args[0]
 	// fun needs to be a callable
 	if call, ok := fun.(Callable); ok {
-		// If the arity of the callable is one, we only pass the value
-		if call.Arity() == 1 {
+		// If the arity of the callable is zero or one, we only pass the value. A closure
+		// without parameters accesses it through the implicit `it` variable
+		if call.Arity() <= 1 {
 			for _, value := range m.instance {
 				call.Call(value)
 			}
@@ -23,7 +27,7 @@ func (m *Map) Call(args ...interface{}) interface{} {
 			}
 		} else {
 			panic(Error{
-				msg: fmt.Sprintf("Map `loop` accepts a callable with arity 1 or 2, got %d", call.Arity()),
+				msg: fmt.Sprintf("Map `loop` accepts a callable with arity 0, 1 or 2, got %d", call.Arity()),
 			})
 		}
 		return nil
@@ -103,8 +107,9 @@ func (a *Array) Call(args ...interface{}) interface{} {
 	fun := args[0]
 	// fun needs to be a callable
 	if call, ok := fun.(Callable); ok {
-		// If the arity of the callable is one, we only pass the value
-		if call.Arity() == 1 {
+		// If the arity of the callable is zero or one, we only pass the value. A closure
+		// without parameters accesses it through the implicit `it` variable
+		if call.Arity() <= 1 {
 			for _, value := range a.entries {
 				call.Call(value)
 			}
@@ -114,7 +119,7 @@ func (a *Array) Call(args ...interface{}) interface{} {
 			}
 		} else {
 			panic(Error{
-				msg: fmt.Sprintf("Array `loop` accepts a callable with arity 1 or 2, got %d", call.Arity()),
+				msg: fmt.Sprintf("Array `loop` accepts a callable with arity 0, 1 or 2, got %d", call.Arity()),
 			})
 		}
 		return nil
@@ -132,15 +137,82 @@ func (a *Array) Arity() int {
 
 // GetValue indexes into the list
 func (a *Array) GetValue(key interface{}) interface{} {
-	index, ok := key.(float64)
+	index, ok := toNumber(key)
 	if !ok {
 		panic(Error{
 			msg: "Expected an int as a list index",
 		})
 	}
+	if int(index) < 0 || int(index) >= len(a.entries) {
+		panic(Error{
+			msg: fmt.Sprintf("Index %d out of range for an array of size %d", int(index), len(a.entries)),
+		})
+	}
 	return a.entries[int(index)]
 }
 
 func (a *Array) String() string {
 	return fmt.Sprintf("#Array %v", a.entries)
 }
+
+// native converts a runtime value into it's native go representation. Maps and arrays are
+// converted recursively
+func native(val interface{}) interface{} {
+	switch v := val.(type) {
+	case *Map:
+		m := make(map[string]interface{}, len(v.instance))
+		for key, value := range v.instance {
+			m[key] = native(value)
+		}
+		return m
+	case *Array:
+		a := make([]interface{}, len(v.entries))
+		for index, entry := range v.entries {
+			a[index] = native(entry)
+		}
+		return a
+	case fmt.Stringer:
+		return v.String()
+	}
+	return val
+}
+
+// runtimeValue converts a native go value into it's runtime representation, it's the inverse of
+// native. Slices, arrays and maps with string keys are converted recursively, numbers of any kind
+// are converted into float64. Runtime values and other go values are returned as is
+func runtimeValue(val interface{}) interface{} {
+	switch val.(type) {
+	case nil, string, bool, float64, *Map, *Array:
+		return val
+	}
+	v := reflect.ValueOf(val)
+	switch v.Kind() {
+	case reflect.Map:
+		if v.Type().Key().Kind() != reflect.String {
+			return val
+		}
+		m := &Map{instance: make(map[string]interface{}, v.Len())}
+		iter := v.MapRange()
+		for iter.Next() {
+			m.instance[iter.Key().String()] = runtimeValue(iter.Value().Interface())
+		}
+		return m
+	case reflect.Slice, reflect.Array:
+		a := &Array{entries: make([]interface{}, v.Len())}
+		for index := range a.entries {
+			a.entries[index] = runtimeValue(v.Index(index).Interface())
+		}
+		return a
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return float64(v.Uint())
+	case reflect.Float32, reflect.Float64:
+		return v.Float()
+	case reflect.String:
+		return v.String()
+	case reflect.Bool:
+		return v.Bool()
+	}
+	return val
+}
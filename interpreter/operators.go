@@ -0,0 +1,187 @@
+package interpreter
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/kingzbauer/scraperlang/parser"
+	"github.com/kingzbauer/scraperlang/token"
+)
+
+// VisitBinaryExpr evaluates arithmetic, comparison and logical operators. The logical operators
+// short circuit and evaluate to the operand that decided the result
+func (i *Interpreter) VisitBinaryExpr(expr parser.BinaryExpr, e parser.Environment) interface{} {
+	left := expr.Left.Accept(i, e)
+	switch expr.Operator.Type {
+	case token.And:
+		if !truthy(left) {
+			return left
+		}
+		return expr.Right.Accept(i, e)
+	case token.Or:
+		if truthy(left) {
+			return left
+		}
+		return expr.Right.Accept(i, e)
+	}
+
+	right := expr.Right.Accept(i, e)
+	switch expr.Operator.Type {
+	case token.EqualEqual:
+		return equal(left, right)
+	case token.BangEqual:
+		return !equal(left, right)
+	case token.Plus:
+		return add(expr.Operator, left, right)
+	case token.Less, token.LessEqual, token.Greater, token.GreaterEqual:
+		return compare(expr.Operator, left, right)
+	}
+
+	a, b := numberOperands(expr.Operator, left, right)
+	switch expr.Operator.Type {
+	case token.Minus:
+		return a - b
+	case token.Star:
+		return a * b
+	case token.Slash:
+		if b == 0 {
+			panic(Error{msg: "division by zero", token: expr.Operator})
+		}
+		return a / b
+	case token.Percent:
+		if b == 0 {
+			panic(Error{msg: "modulo by zero", token: expr.Operator})
+		}
+		return math.Mod(a, b)
+	}
+	panic(Error{
+		msg:   fmt.Sprintf("Unsupported binary operator %q", expr.Operator.Lexeme),
+		token: expr.Operator,
+	})
+}
+
+// VisitUnaryExpr evaluates a negation or a logical not
+func (i *Interpreter) VisitUnaryExpr(expr parser.UnaryExpr, e parser.Environment) interface{} {
+	right := expr.Right.Accept(i, e)
+	switch expr.Operator.Type {
+	case token.Not:
+		return !truthy(right)
+	case token.Minus:
+		n, ok := toNumber(right)
+		if !ok {
+			panic(Error{
+				msg:   fmt.Sprintf("'-' expects a number operand, got %v", right),
+				token: expr.Operator,
+			})
+		}
+		return -n
+	}
+	panic(Error{
+		msg:   fmt.Sprintf("Unsupported unary operator %q", expr.Operator.Lexeme),
+		token: expr.Operator,
+	})
+}
+
+// VisitGroupingExpr evaluates the enclosed expression
+func (i *Interpreter) VisitGroupingExpr(expr parser.GroupingExpr, e parser.Environment) interface{} {
+	return expr.Expr.Accept(i, e)
+}
+
+// truthy decides whether a value is considered true. nil, false, empty strings and empty arrays and
+// maps are false, every other value is true
+func truthy(val interface{}) bool {
+	switch v := val.(type) {
+	case nil:
+		return false
+	case bool:
+		return v
+	case string:
+		return v != ""
+	case *Array:
+		return len(v.entries) > 0
+	case *Map:
+		return len(v.instance) > 0
+	}
+	return true
+}
+
+// toNumber converts the numeric runtime values to a float64
+func toNumber(val interface{}) (float64, bool) {
+	switch v := val.(type) {
+	case float64:
+		return v, true
+	case int:
+		return float64(v), true
+	}
+	return 0, false
+}
+
+func numberOperands(operator *token.Token, left, right interface{}) (float64, float64) {
+	a, leftOk := toNumber(left)
+	b, rightOk := toNumber(right)
+	if !leftOk || !rightOk {
+		panic(Error{
+			msg:   fmt.Sprintf("'%s' expects number operands, got %v and %v", operator.Lexeme, left, right),
+			token: operator,
+		})
+	}
+	return a, b
+}
+
+// equal compares two runtime values. Numbers are compared by value, maps and arrays by identity
+func equal(left, right interface{}) bool {
+	a, leftOk := toNumber(left)
+	b, rightOk := toNumber(right)
+	if leftOk && rightOk {
+		return a == b
+	}
+	return left == right
+}
+
+// add sums numbers, concatenates arrays and concatenates strings. If either operand is a string,
+// the other is converted to a string
+func add(operator *token.Token, left, right interface{}) interface{} {
+	_, leftString := left.(string)
+	_, rightString := right.(string)
+	if leftString || rightString {
+		return stringify(left) + stringify(right)
+	}
+	leftArray, leftOk := left.(*Array)
+	rightArray, rightOk := right.(*Array)
+	if leftOk && rightOk {
+		entries := make([]interface{}, 0, len(leftArray.entries)+len(rightArray.entries))
+		entries = append(entries, leftArray.entries...)
+		return &Array{entries: append(entries, rightArray.entries...)}
+	}
+	a, b := numberOperands(operator, left, right)
+	return a + b
+}
+
+// compare orders either two numbers or two strings
+func compare(operator *token.Token, left, right interface{}) bool {
+	leftString, leftOk := left.(string)
+	rightString, rightOk := right.(string)
+	if leftOk && rightOk {
+		switch operator.Type {
+		case token.Less:
+			return leftString < rightString
+		case token.LessEqual:
+			return leftString <= rightString
+		case token.Greater:
+			return leftString > rightString
+		default:
+			return leftString >= rightString
+		}
+	}
+	a, b := numberOperands(operator, left, right)
+	switch operator.Type {
+	case token.Less:
+		return a < b
+	case token.LessEqual:
+		return a <= b
+	case token.Greater:
+		return a > b
+	default:
+		return a >= b
+	}
+}
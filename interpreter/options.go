@@ -0,0 +1,110 @@
+package interpreter
+
+import (
+	"io"
+	"log"
+	"net/http"
+)
+
+// defaultPoolSize is the number of units of work executed concurrently by default
+const defaultPoolSize = 10
+
+// Options configures an interpreter created with NewWithOptions. The zero value of every field
+// selects it's default
+type Options struct {
+	// PoolSize is the number of units of work, i.e requests and the tagged closures handling their
+	// responses, executed concurrently. Defaults to 10
+	PoolSize int
+	// Client makes the requests of the script. Defaults to http.DefaultClient
+	Client *http.Client
+	// Output is written to by print statements and the default sink. Defaults to stdout
+	Output io.Writer
+	// OnError is called with every error recorded by the units of work as it's raised, the errors
+	// are still retrieved with Err. It's called from the goroutines of the pool
+	OnError func(error)
+	// Logger logs the requests made by the script and the ones dropped by the crawl depth limits.
+	// Nothing is logged by default
+	Logger *log.Logger
+	// Builtins are the values made available to scripts as global variables, see
+	// Interpreter.Define
+	Builtins map[string]interface{}
+	// Sinks receive the records of emit statements. The records are written to Output as json
+	// lines when both Sinks and OutputFormats are empty
+	Sinks []Sink
+	// OutputFormats are the formats the records of emit statements are written to Output in. The
+	// records are written in between the output of print statements, never within it
+	OutputFormats []string
+}
+
+// Option configures an interpreter created with New
+type Option func(*Options)
+
+// WithPoolSize sets the number of units of work executed concurrently
+func WithPoolSize(size int) Option {
+	return func(opts *Options) {
+		opts.PoolSize = size
+	}
+}
+
+// WithHTTPClient sets the client making the requests of the script
+func WithHTTPClient(client *http.Client) Option {
+	return func(opts *Options) {
+		opts.Client = client
+	}
+}
+
+// WithTransport makes the requests of the script with a client using the transport, e.g the
+// transport of an httptest.Server
+func WithTransport(transport http.RoundTripper) Option {
+	return func(opts *Options) {
+		opts.Client = &http.Client{Transport: transport}
+	}
+}
+
+// WithOutput sets the writer print statements and the default sink write to
+func WithOutput(w io.Writer) Option {
+	return func(opts *Options) {
+		opts.Output = w
+	}
+}
+
+// WithErrorHandler sets the function called with every error recorded by the units of work
+func WithErrorHandler(handler func(error)) Option {
+	return func(opts *Options) {
+		opts.OnError = handler
+	}
+}
+
+// WithLogger sets the logger the requests are logged to
+func WithLogger(logger *log.Logger) Option {
+	return func(opts *Options) {
+		opts.Logger = logger
+	}
+}
+
+// WithSinks adds sinks receiving the records of emit statements
+func WithSinks(sinks ...Sink) Option {
+	return func(opts *Options) {
+		opts.Sinks = append(opts.Sinks, sinks...)
+	}
+}
+
+// WithOutputSinks writes the records of emit statements to the output in every one of the formats,
+// see CheckFormat
+func WithOutputSinks(formats ...string) Option {
+	return func(opts *Options) {
+		opts.OutputFormats = append(opts.OutputFormats, formats...)
+	}
+}
+
+// WithBuiltins makes the values available to scripts as global variables, see Interpreter.Define
+func WithBuiltins(builtins map[string]interface{}) Option {
+	return func(opts *Options) {
+		if opts.Builtins == nil {
+			opts.Builtins = make(map[string]interface{}, len(builtins))
+		}
+		for name, value := range builtins {
+			opts.Builtins[name] = value
+		}
+	}
+}
@@ -0,0 +1,48 @@
+package interpreter
+
+import "sync"
+
+// workQueue holds the units of work waiting for a free worker of the pool. Units of work are
+// submitted to the pool from the goroutine of the queue rather than from the workers making the
+// requests, a worker blocking on a full pool would otherwise wait on itself once every worker
+// does the same. The goroutine only runs while there is pending work
+type workQueue struct {
+	mu      sync.Mutex
+	pending []func()
+	running bool
+	// submit hands the unit of work to the pool, blocking until a worker is available
+	submit func(work func())
+}
+
+func newWorkQueue(submit func(work func())) *workQueue {
+	return &workQueue{submit: submit}
+}
+
+// push queues the unit of work without blocking
+func (q *workQueue) push(work func()) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.pending = append(q.pending, work)
+	if !q.running {
+		q.running = true
+		go q.run()
+	}
+}
+
+// run submits the pending units of work in the order they were queued until none is left
+func (q *workQueue) run() {
+	for {
+		q.mu.Lock()
+		if len(q.pending) == 0 {
+			q.running = false
+			q.mu.Unlock()
+			return
+		}
+		work := q.pending[0]
+		q.pending[0] = nil
+		q.pending = q.pending[1:]
+		q.mu.Unlock()
+
+		q.submit(work)
+	}
+}
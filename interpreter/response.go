@@ -0,0 +1,83 @@
+package interpreter
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Response is the runtime representation of a http response. It is made available to tagged
+// closures through the `response` variable
+type Response struct {
+	url         string
+	status      int
+	headers     *Map
+	body        string
+	contentType string
+	elapsed     time.Duration
+	request     *Map
+}
+
+// newResponse creates a runtime response from the http response and it's already read body
+func newResponse(res *http.Response, body []byte, elapsed time.Duration) *Response {
+	return &Response{
+		url:         res.Request.URL.String(),
+		status:      res.StatusCode,
+		headers:     headersToMap(res.Header),
+		body:        string(body),
+		contentType: res.Header.Get("Content-Type"),
+		elapsed:     elapsed,
+		request: &Map{instance: map[string]interface{}{
+			"method":  res.Request.Method,
+			"url":     res.Request.URL.String(),
+			"headers": headersToMap(res.Request.Header),
+		}},
+	}
+}
+
+// Get implements the Accessor interface for the response.
+//
+//	1. `url` the final url of the response after any redirects
+//	2. `status` the http status code
+//	3. `headers` a map of the response headers
+//	4. `body` the raw response body
+//	5. `content_type` the value of the Content-Type header
+//	6. `elapsed` the time taken by the request in seconds
+//	7. `request` a map of the method, url and headers of the request that produced the response
+func (r *Response) Get(attr string) interface{} {
+	switch attr {
+	case "url":
+		return r.url
+	case "status":
+		return r.status
+	case "headers":
+		return r.headers
+	case "body":
+		return r.body
+	case "content_type":
+		return r.contentType
+	case "elapsed":
+		return r.elapsed.Seconds()
+	case "request":
+		return r.request
+	default:
+		panic(Error{
+			msg: fmt.Sprintf("Response does not have an attribute %q", attr),
+		})
+	}
+}
+
+func (r *Response) String() string {
+	return fmt.Sprintf("#Response %d %s", r.status, r.url)
+}
+
+// headersToMap converts http headers into a runtime map. Multiple values of the same header are
+// joined with a comma
+func headersToMap(header http.Header) *Map {
+	m := &Map{instance: make(map[string]interface{}, len(header))}
+	for key, values := range header {
+		m.instance[key] = strings.Join(values, ", ")
+	}
+	return m
+}
@@ -1,10 +1,18 @@
 package interpreter
 
 import (
+	"bytes"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"net/http"
+	"net/url"
 	"strings"
+	"time"
+
+	"github.com/kingzbauer/scraperlang/diag"
+	"github.com/kingzbauer/scraperlang/token"
 )
 
 // This package contains the set of functions, structs that are related to creating http request jobs
@@ -12,64 +20,214 @@ import (
 // A set of errors defining the different runtime errors
 var (
 	ErrMissingURLScheme = errors.New("Missing a valid URL scheme")
+	ErrMissingInit      = errors.New("Missing 'init' tagged closure")
 )
 
-type getWorkConfig struct {
-	tag     string
-	url     string
-	headers map[string]interface{}
+// RequestError is recorded when a http request could not be completed, e.g the URL is invalid or
+// the server could not be reached
+type RequestError struct {
+	Method string
+	URL    string
+	Err    error
+	token  *token.Token
+}
+
+func (err RequestError) Error() string {
+	return err.Diagnostic().Error()
 }
 
-// newGetWork returns a unit of work that is created when we encounter a get expression. It is
-// then dispatched to it's own goroutine
+// Unwrap returns the cause of the failed request
+func (err RequestError) Unwrap() error {
+	return err.Err
+}
+
+// Diagnostic implements the diag.Diagnoser interface
+func (err RequestError) Diagnostic() diag.Diagnostic {
+	return err.token.Diagnostic(diag.Error, fmt.Sprintf("%s %s: %s", err.Method, err.URL, err.Err))
+}
+
+type requestWorkConfig struct {
+	method string
+	// keyword is the request keyword e.g get, used to locate the errors of the unit of work
+	keyword     *token.Token
+	tag         string
+	url         string
+	headers     http.Header
+	body        []byte
+	contentType string
+	depth       int
+}
+
+// newRequestWork returns a unit of work that is created when we encounter a request expression e.g get, post.
+// It is then dispatched to it's own goroutine
 // The unit of work is responsible of calling wg.Done when it's done executing so as to allow the main
-// interpreter goroutine to exit when all work is complete
-//
-// TODO: There are a couple todos here especially with regards to handling error paths
-func (i *Interpreter) newGetWork(cfg getWorkConfig) func() {
+// interpreter goroutine to exit when all work is complete. Runtime errors are recorded before
+// calling wg.Done, so they can be retrieved with Err once the interpreter is done waiting.
+// Requests that can't be completed are handed to the 'error' tagged closure if the script declares
+// one, otherwise they are recorded as a RequestError and can be retrieved with Err
+func (i *Interpreter) newRequestWork(cfg requestWorkConfig) func() {
 	return func() {
-		defer i.wg.Done()
+		defer func() {
+			if val := recover(); val != nil {
+				if err, ok := val.(error); ok {
+					i.addErr(err)
+				} else {
+					i.addErr(fmt.Errorf("%v", val))
+				}
+			}
+			i.wg.Done()
+		}()
 
-		// Make sure the url has a valid scheme
-		parts := strings.SplitN(cfg.url, ":", 2)
-		if len(parts) != 2 {
-			// TODO: Have an error handling mechanism
-			return
-		} else if !in(parts[0], []string{"http", "https"}) {
-			// TODO: Have an error handling mechanism
-			return
-		}
-		req, err := http.NewRequest(http.MethodGet, cfg.url, nil)
+		vars, err := i.fetch(cfg)
 		if err != nil {
+			// The crawl depth limit of the 'error' tagged closure bounds the retries of failed requests
+			limit, limited := i.depthLimit("error")
+			if closure, ok := i.taggedClosures["error"]; ok && (!limited || cfg.depth <= limit) {
+				i.logf("%s", err)
+				closure.Accept(i, i.newTaggedEnvironment(map[string]interface{}{
+					"url":    cfg.url,
+					"method": cfg.method,
+					"cause":  err.(RequestError).Err.Error(),
+					"tag":    cfg.tag,
+				}, cfg.depth))
+				return
+			}
+			i.addErr(err)
 			return
 		}
-		headers := map[string][]string{}
-		if cfg.headers != nil {
-			for key, value := range cfg.headers {
-				switch t := value.(type) {
-				case string:
-					headers[key] = []string{t}
-				case []string:
-					headers[key] = t
-				}
+		env := i.newTaggedEnvironment(vars, cfg.depth)
+		// Missing tagged closures are reported by the Resolver before execution starts
+		if closure, ok := i.taggedClosures[cfg.tag]; ok {
+			closure.Accept(i, env)
+		} else {
+			panic(Error{
+				msg:   fmt.Sprintf("Unable to find the tagged closure %q", cfg.tag),
+				token: cfg.keyword,
+			})
+		}
+	}
+}
+
+// fetch makes the request and returns the variables a tagged closure receives for the response:
+// response, status, headers, content and jq. The error is always a RequestError
+func (i *Interpreter) fetch(cfg requestWorkConfig) (map[string]interface{}, error) {
+	fail := func(err error) (map[string]interface{}, error) {
+		return nil, RequestError{Method: cfg.method, URL: cfg.url, Err: err, token: cfg.keyword}
+	}
+
+	// Make sure the url has a valid scheme
+	parts := strings.SplitN(cfg.url, ":", 2)
+	if len(parts) != 2 || !in(parts[0], []string{"http", "https"}) {
+		return fail(ErrMissingURLScheme)
+	}
+	var body io.Reader
+	if cfg.body != nil {
+		body = bytes.NewReader(cfg.body)
+	}
+	req, err := http.NewRequest(cfg.method, cfg.url, body)
+	if err != nil {
+		return fail(err)
+	}
+	if cfg.headers != nil {
+		req.Header = cfg.headers.Clone()
+	}
+	// The Content-Type provided by the script takes precedence over the one of the encoded body
+	if cfg.contentType != "" && req.Header.Get("Content-Type") == "" {
+		req.Header.Set("Content-Type", cfg.contentType)
+	}
+
+	start := time.Now()
+	res, err := i.client.Do(req)
+	if err != nil {
+		// The method and url are already part of the RequestError
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		return fail(err)
+	}
+	content, err := io.ReadAll(res.Body)
+	res.Body.Close()
+	if err != nil {
+		return fail(err)
+	}
+	response := newResponse(res, content, time.Since(start))
+	i.logf("%s %s: %d in %s", cfg.method, cfg.url, res.StatusCode, response.elapsed)
+	selection, err := NewSelection(content)
+	if err != nil {
+		return fail(err)
+	}
+	return map[string]interface{}{
+		"response": response,
+		"status":   response.status,
+		"headers":  response.headers,
+		"content":  selection,
+		"jq":       selection.Get("jq"),
+	}, nil
+}
+
+// requestHeaders converts the map of headers provided by the script into http headers. Header
+// names are case insensitive, an array value sets multiple values for the same header
+func requestHeaders(m *Map) http.Header {
+	headers := http.Header{}
+	for key, value := range m.instance {
+		if values, ok := value.(*Array); ok {
+			headers.Del(key)
+			for _, entry := range values.entries {
+				headers.Add(key, fmt.Sprint(entry))
 			}
+		} else {
+			headers.Set(key, fmt.Sprint(value))
 		}
-		req.Header = headers
-
-		if res, err := http.DefaultClient.Do(req); err == nil {
-			env := NewEnvironment(map[string]interface{}{
-				"status": res.StatusCode,
-			}, nil)
-			// TODO: This will be handled by the Resolver by doing a pre-semantic analysis
-			if closure, ok := i.taggedClosures[cfg.tag]; ok {
-				closure.Accept(i, env)
+	}
+	return headers
+}
+
+// encodeBody converts the runtime value of a request body into the bytes sent over the wire and
+// the content type to use if the request headers don't provide one.
+//
+//	1. A string is sent as is
+//	2. A map is form encoded, unless the Content-Type header is a json content type
+//	3. An array is json encoded
+func encodeBody(method string, val interface{}, headers http.Header) ([]byte, string) {
+	contentType := headers.Get("Content-Type")
+	switch v := val.(type) {
+	case nil:
+		return nil, ""
+	case string:
+		return []byte(v), ""
+	case *Map:
+		if strings.Contains(contentType, "json") {
+			return encodeJSON(method, v), "application/json"
+		}
+		form := url.Values{}
+		for key, value := range v.instance {
+			if values, ok := value.(*Array); ok {
+				for _, entry := range values.entries {
+					form.Add(key, fmt.Sprint(entry))
+				}
 			} else {
-				panic(Error{
-					msg: fmt.Sprintf("Unable to find the tagged closure %q\n", cfg.tag),
-				})
+				form.Add(key, fmt.Sprint(value))
 			}
 		}
+		return []byte(form.Encode()), "application/x-www-form-urlencoded"
+	case *Array:
+		return encodeJSON(method, v), "application/json"
+	default:
+		panic(Error{
+			msg: fmt.Sprintf("'%s' expects a string, map or array as the request body, got %v", method, val),
+		})
+	}
+}
+
+func encodeJSON(method string, val interface{}) []byte {
+	content, err := json.Marshal(native(val))
+	if err != nil {
+		panic(Error{
+			msg: fmt.Sprintf("'%s' unable to json encode the request body: %s", method, err),
+		})
 	}
+	return content
 }
 
 func in(val string, array []string) bool {
@@ -0,0 +1,116 @@
+package lsp
+
+// builtin documents a built-in variable or keyword
+type builtin struct {
+	signature string
+	doc       string
+	keyword   bool
+}
+
+// builtins are the variables made available to tagged closures by the interpreter and the keywords
+// of the language
+var builtins = map[string]builtin{
+	"response": {
+		signature: "response",
+		doc:       "The response of the request that invoked the tagged closure. Attributes: `url`, `status`, `headers`, `body`, `content_type`, `elapsed` and `request`.",
+	},
+	"status": {
+		signature: "status",
+		doc:       "The http status code of the response.",
+	},
+	"headers": {
+		signature: "headers",
+		doc:       "A map of the response headers, multiple values of a header are joined with a comma.",
+	},
+	"content": {
+		signature: "content",
+		doc:       "The html document of the response. Attributes: `jq`, `text` and `html`.",
+	},
+	"jq": {
+		signature: "jq selector",
+		doc:       "Returns an array of the html nodes of the response matching the css selector.",
+	},
+	"depth": {
+		signature: "depth",
+		doc:       "The crawl depth of the tagged closure, `0` for `init` and one more than the requesting closure otherwise.",
+	},
+	"it": {
+		signature: "it",
+		doc:       "The argument of a closure that is declared without parameters.",
+	},
+	"get": {
+		signature: "@tag get url, headers?",
+		doc:       "Requests the url, or every url of an array, and invokes the tagged closure with the response. Untagged requests invoke `default`.",
+		keyword:   true,
+	},
+	"post": {
+		signature: "@tag post url, body?, headers?",
+		doc:       "Sends a POST request. A map body is form encoded unless the Content-Type header is json, an array body is json encoded.",
+		keyword:   true,
+	},
+	"put": {
+		signature: "@tag put url, body?, headers?",
+		doc:       "Sends a PUT request, the body is encoded the same way as `post`.",
+		keyword:   true,
+	},
+	"patch": {
+		signature: "@tag patch url, body?, headers?",
+		doc:       "Sends a PATCH request, the body is encoded the same way as `post`.",
+		keyword:   true,
+	},
+	"delete": {
+		signature: "@tag delete url, body?, headers?",
+		doc:       "Sends a DELETE request.",
+		keyword:   true,
+	},
+	"head": {
+		signature: "@tag head url, body?, headers?",
+		doc:       "Sends a HEAD request.",
+		keyword:   true,
+	},
+	"print": {
+		signature: "print value, ...",
+		doc:       "Prints the values separated by a space.",
+		keyword:   true,
+	},
+	"emit": {
+		signature: "emit {key: value, ...}",
+		doc:       "Writes the map record to the outputs selected with `sl run -emit`, JSON lines on stdout by default.",
+		keyword:   true,
+	},
+	"return": {
+		signature: "return value?",
+		doc:       "Returns from the enclosing closure.",
+		keyword:   true,
+	},
+	"if": {
+		signature: "if condition { } else { }",
+		doc:       "Executes the branch selected by the truthiness of the condition. `nil`, `false` and empty strings, arrays and maps are falsy.",
+		keyword:   true,
+	},
+	"try": {
+		signature: "try { } catch err { }",
+		doc:       "Executes the body and, if it raises a runtime error, the catch body with `err` set to a map of the error `message`, `line` and `column`.",
+		keyword:   true,
+	},
+	"for": {
+		signature: "for key, value in iterable { }",
+		doc:       "Iterates over an array by index and value or over a map by key and value, in key order.",
+		keyword:   true,
+	},
+	"while": {
+		signature: "while condition { }",
+		doc:       "Executes the body for as long as the condition is truthy.",
+		keyword:   true,
+	},
+	"break": {
+		signature: "break",
+		doc:       "Exits the enclosing loop.",
+		keyword:   true,
+	},
+	"continue": {
+		signature: "continue",
+		doc:       "Skips to the next iteration of the enclosing loop.",
+		keyword:   true,
+	},
+}
@@ -0,0 +1,169 @@
+package lsp
+
+import (
+	"sort"
+	"strings"
+	"unicode/utf16"
+
+	"github.com/kingzbauer/scraperlang/diag"
+	"github.com/kingzbauer/scraperlang/parser"
+	"github.com/kingzbauer/scraperlang/resolver"
+	"github.com/kingzbauer/scraperlang/token"
+)
+
+// document is an open text document along with the result of analysing it
+type document struct {
+	uri   string
+	lines []string
+	// tokens are the tokens of the document, empty if it could not be scanned
+	tokens token.Tokens
+	ast    []parser.Expr
+	// closures are the tagged closures of the document by name
+	closures map[string]parser.TaggedClosure
+	// variables are the names of the variables declared in the document
+	variables   []string
+	diagnostics []Diagnostic
+}
+
+// newDocument scans, parses and resolves the text. Errors and warnings of every stage are kept as
+// the diagnostics of the document
+func newDocument(uri, text string) *document {
+	d := &document{
+		uri:         uri,
+		lines:       strings.Split(text, "\n"),
+		closures:    make(map[string]parser.TaggedClosure),
+		diagnostics: []Diagnostic{},
+	}
+
+	tokens, err := token.NewScanner([]byte(text)).ScanTokens()
+	if err != nil {
+		d.addDiagnostics(err)
+		return d
+	}
+	d.tokens = tokens
+
+	p := parser.New(tokens)
+	ast, err := p.Parse()
+	if err != nil {
+		d.addDiagnostics(err)
+	}
+	d.addDiagnostics(p.Err()...)
+	d.ast = ast
+	d.index()
+
+	// Resolving a partial AST would report errors caused by the syntax errors
+	if err == nil && !p.HasErrs() {
+		r := resolver.New(ast)
+		r.Resolve()
+		d.addDiagnostics(r.Err()...)
+		d.addDiagnostics(r.Warnings()...)
+	}
+	return d
+}
+
+func (d *document) addDiagnostics(errs ...error) {
+	for _, err := range errs {
+		diagnostic := diag.From(err)
+		severity := severityError
+		if diagnostic.Severity == diag.Warning {
+			severity = severityWarning
+		}
+		var rng Range
+		if diagnostic.HasPosition() {
+			rng = Range{Start: d.position(diagnostic.Start), End: d.position(diagnostic.End)}
+		}
+		d.diagnostics = append(d.diagnostics, Diagnostic{
+			Range:    rng,
+			Severity: severity,
+			Source:   "sl",
+			Message:  diagnostic.Msg,
+		})
+	}
+}
+
+// index records the tagged closures and the declared variables
+func (d *document) index() {
+	variables := make(map[string]bool)
+	for _, expr := range d.ast {
+		closure, ok := expr.(parser.TaggedClosure)
+		if !ok {
+			continue
+		}
+		d.closures[closure.Name.Lexeme] = closure
+		parser.Inspect(closure, func(expr parser.Expr) bool {
+			switch e := expr.(type) {
+			case parser.AssignExpr:
+				variables[e.Name.Lexeme] = true
+			case parser.ForExpr:
+				if e.Key != nil {
+					variables[e.Key.Lexeme] = true
+				}
+				variables[e.Value.Lexeme] = true
+			case parser.TryExpr:
+				variables[e.Name.Lexeme] = true
+			case parser.ClosureExpr:
+				for _, param := range e.Params {
+					variables[param.Lexeme] = true
+				}
+			}
+			return true
+		})
+	}
+	for name := range variables {
+		d.variables = append(d.variables, name)
+	}
+	sort.Strings(d.variables)
+}
+
+// position converts a byte based position into an LSP position
+func (d *document) position(pos diag.Position) Position {
+	if pos.Line >= len(d.lines) {
+		return Position{Line: pos.Line}
+	}
+	line := d.lines[pos.Line]
+	column := pos.Column
+	if column > len(line) {
+		column = len(line)
+	}
+	return Position{Line: pos.Line, Character: len(utf16.Encode([]rune(line[:column])))}
+}
+
+// offset converts an LSP position into the byte column within the line
+func (d *document) offset(pos Position) int {
+	if pos.Line >= len(d.lines) {
+		return 0
+	}
+	units := 0
+	for index, r := range d.lines[pos.Line] {
+		if units >= pos.Character {
+			return index
+		}
+		units += len(utf16.Encode([]rune{r}))
+	}
+	return len(d.lines[pos.Line])
+}
+
+// tokenRange returns the range spanned by the token
+func (d *document) tokenRange(t *token.Token) Range {
+	diagnostic := t.Diagnostic(diag.Error, "")
+	return Range{Start: d.position(diagnostic.Start), End: d.position(diagnostic.End)}
+}
+
+// tokenAt returns the token under the position if any
+func (d *document) tokenAt(pos Position) *token.Token {
+	column := d.offset(pos)
+	for _, t := range d.tokens {
+		if t.Line == pos.Line && t.Column <= column && column <= t.Column+len(t.Lexeme) && t.Type != token.Newline {
+			return t
+		}
+	}
+	return nil
+}
+
+// prefix returns the text of the line up to the position
+func (d *document) prefix(pos Position) string {
+	if pos.Line >= len(d.lines) {
+		return ""
+	}
+	return d.lines[pos.Line][:d.offset(pos)]
+}
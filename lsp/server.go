@@ -0,0 +1,313 @@
+package lsp
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/textproto"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/kingzbauer/scraperlang/parser"
+	"github.com/kingzbauer/scraperlang/token"
+)
+
+// Server is a Language Server Protocol server for scraperlang scripts. It speaks JSON-RPC over a
+// pair of streams, usually stdin and stdout, and supports:
+//
+//	1. Diagnostics from the scanner, parser and resolver, published whenever a document changes
+//	2. Go to definition from an @tag to the tagged closure it refers to
+//	3. Hover documentation for the built-in variables and keywords
+//	4. Completion of variables, keywords and, after an '@', tags
+//	5. Document symbols for every tagged closure
+//
+// Documents are synchronized in full on every change
+type Server struct {
+	in       *bufio.Reader
+	out      io.Writer
+	docs     map[string]*document
+	shutdown bool
+}
+
+// NewServer creates a server that reads requests from in and writes responses to out
+func NewServer(in io.Reader, out io.Writer) *Server {
+	return &Server{
+		in:   bufio.NewReader(in),
+		out:  out,
+		docs: make(map[string]*document),
+	}
+}
+
+// Serve handles requests until the client sends the exit notification or closes the input stream
+func (s *Server) Serve() error {
+	for {
+		content, err := s.read()
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+
+		var req request
+		if err := json.Unmarshal(content, &req); err != nil {
+			if err := s.replyError(nil, codeParseError, err.Error()); err != nil {
+				return err
+			}
+			continue
+		}
+		if req.Method == "exit" {
+			return nil
+		}
+		if err := s.handle(req); err != nil {
+			return err
+		}
+	}
+}
+
+// read returns the content of the next message, which is framed by a Content-Length header
+func (s *Server) read() ([]byte, error) {
+	headers, err := textproto.NewReader(s.in).ReadMIMEHeader()
+	if err != nil {
+		return nil, err
+	}
+	length, err := strconv.Atoi(headers.Get("Content-Length"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid Content-Length header: %w", err)
+	}
+	content := make([]byte, length)
+	_, err = io.ReadFull(s.in, content)
+	return content, err
+}
+
+func (s *Server) write(val interface{}) error {
+	content, err := json.Marshal(val)
+	if err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintf(s.out, "Content-Length: %d\r\n\r\n", len(content)); err != nil {
+		return err
+	}
+	_, err = s.out.Write(content)
+	return err
+}
+
+func (s *Server) reply(id *json.RawMessage, result interface{}) error {
+	return s.write(response{JSONRPC: "2.0", ID: id, Result: result})
+}
+
+func (s *Server) replyError(id *json.RawMessage, code int, msg string) error {
+	return s.write(errorResponse{JSONRPC: "2.0", ID: id, Error: responseError{Code: code, Message: msg}})
+}
+
+func (s *Server) notify(method string, params interface{}) error {
+	return s.write(notification{JSONRPC: "2.0", Method: method, Params: params})
+}
+
+// handle dispatches the request to it's handler. Unknown notifications are ignored
+func (s *Server) handle(req request) error {
+	if s.shutdown && req.ID != nil {
+		return s.replyError(req.ID, codeInvalidRequest, "the server is shutting down")
+	}
+
+	var result interface{}
+	var err error
+	switch req.Method {
+	case "initialize":
+		result = map[string]interface{}{
+			"capabilities": map[string]interface{}{
+				"textDocumentSync":       1,
+				"definitionProvider":     true,
+				"hoverProvider":          true,
+				"documentSymbolProvider": true,
+				"completionProvider": map[string]interface{}{
+					"triggerCharacters": []string{"@"},
+				},
+			},
+			"serverInfo": map[string]string{"name": "sl"},
+		}
+	case "shutdown":
+		s.shutdown = true
+	case "textDocument/didOpen":
+		var params didOpenParams
+		if err = json.Unmarshal(req.Params, &params); err == nil {
+			return s.update(params.TextDocument.URI, params.TextDocument.Text)
+		}
+	case "textDocument/didChange":
+		var params didChangeParams
+		if err = json.Unmarshal(req.Params, &params); err == nil && len(params.ContentChanges) > 0 {
+			return s.update(params.TextDocument.URI, params.ContentChanges[len(params.ContentChanges)-1].Text)
+		}
+	case "textDocument/didClose":
+		var params didCloseParams
+		if err = json.Unmarshal(req.Params, &params); err == nil {
+			delete(s.docs, params.TextDocument.URI)
+			return s.notify("textDocument/publishDiagnostics", publishDiagnosticsParams{
+				URI:         params.TextDocument.URI,
+				Diagnostics: []Diagnostic{},
+			})
+		}
+	case "textDocument/definition":
+		var params textDocumentPositionParams
+		if err = json.Unmarshal(req.Params, &params); err == nil {
+			result = s.definition(params)
+		}
+	case "textDocument/hover":
+		var params textDocumentPositionParams
+		if err = json.Unmarshal(req.Params, &params); err == nil {
+			result = s.hover(params)
+		}
+	case "textDocument/completion":
+		var params textDocumentPositionParams
+		if err = json.Unmarshal(req.Params, &params); err == nil {
+			result = s.completion(params)
+		}
+	case "textDocument/documentSymbol":
+		var params documentSymbolParams
+		if err = json.Unmarshal(req.Params, &params); err == nil {
+			result = s.symbols(params)
+		}
+	default:
+		if req.ID == nil {
+			return nil
+		}
+		return s.replyError(req.ID, codeMethodNotFound, fmt.Sprintf("method %q is not supported", req.Method))
+	}
+
+	if req.ID == nil {
+		return nil
+	}
+	if err != nil {
+		return s.replyError(req.ID, codeInvalidParams, err.Error())
+	}
+	return s.reply(req.ID, result)
+}
+
+// update analyses the new text of the document and publishes it's diagnostics
+func (s *Server) update(uri, text string) error {
+	doc := newDocument(uri, text)
+	s.docs[uri] = doc
+	return s.notify("textDocument/publishDiagnostics", publishDiagnosticsParams{
+		URI:         uri,
+		Diagnostics: doc.diagnostics,
+	})
+}
+
+// definition returns the location of the tagged closure referred to by the tag under the position
+func (s *Server) definition(params textDocumentPositionParams) interface{} {
+	doc, ok := s.docs[params.TextDocument.URI]
+	if !ok {
+		return nil
+	}
+	t := doc.tokenAt(params.Position)
+	if t == nil || t.Type != token.Tag {
+		return nil
+	}
+	closure, ok := doc.closures[t.Literal.(string)]
+	if !ok {
+		return nil
+	}
+	return Location{URI: doc.uri, Range: doc.tokenRange(closure.Name)}
+}
+
+// hover returns the documentation of the built-in variable or keyword under the position
+func (s *Server) hover(params textDocumentPositionParams) interface{} {
+	doc, ok := s.docs[params.TextDocument.URI]
+	if !ok {
+		return nil
+	}
+	t := doc.tokenAt(params.Position)
+	if t == nil {
+		return nil
+	}
+	builtin, ok := builtins[t.Lexeme]
+	if !ok || (builtin.keyword && t.Type == token.Ident) || (!builtin.keyword && t.Type != token.Ident) {
+		return nil
+	}
+	return hover{
+		Contents: markupContent{Kind: "markdown", Value: fmt.Sprintf("```\n%s\n```\n%s", builtin.signature, builtin.doc)},
+		Range:    doc.tokenRange(t),
+	}
+}
+
+// tagPrefix matches a tag that is being typed at the end of a line
+var tagPrefix = regexp.MustCompile(`@[A-Za-z0-9_]*$`)
+
+// completion returns the tags when completing a tag, otherwise the variables and keywords
+func (s *Server) completion(params textDocumentPositionParams) interface{} {
+	doc, ok := s.docs[params.TextDocument.URI]
+	if !ok {
+		return []completionItem{}
+	}
+	items := []completionItem{}
+	if tagPrefix.MatchString(doc.prefix(params.Position)) {
+		names := make([]string, 0, len(doc.closures))
+		for name := range doc.closures {
+			// The 'error' tagged closure handles failed requests and can't be requested
+			if name != "error" {
+				names = append(names, name)
+			}
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			items = append(items, completionItem{Label: name, Kind: completionFunction, Detail: "tagged closure"})
+		}
+		return items
+	}
+
+	seen := make(map[string]bool)
+	for _, name := range doc.variables {
+		seen[name] = true
+		items = append(items, completionItem{Label: name, Kind: completionVariable})
+	}
+	names := make([]string, 0, len(builtins))
+	for name := range builtins {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if seen[name] {
+			continue
+		}
+		builtin := builtins[name]
+		kind := completionVariable
+		if builtin.keyword {
+			kind = completionKeyword
+		}
+		items = append(items, completionItem{Label: name, Kind: kind, Detail: builtin.signature})
+	}
+	return items
+}
+
+// symbols returns a symbol for every tagged closure of the document
+func (s *Server) symbols(params documentSymbolParams) interface{} {
+	doc, ok := s.docs[params.TextDocument.URI]
+	if !ok {
+		return []documentSymbol{}
+	}
+	symbols := []documentSymbol{}
+	for _, expr := range doc.ast {
+		closure, ok := expr.(parser.TaggedClosure)
+		if !ok {
+			continue
+		}
+		rng := doc.tokenRange(closure.Name)
+		if body, ok := closure.Body.(parser.BodyExpr); ok && body.RightBrace != nil {
+			rng.End = doc.tokenRange(body.RightBrace).End
+		}
+		var options []string
+		for _, option := range closure.Options {
+			options = append(options, option.Name.Lexeme)
+		}
+		symbols = append(symbols, documentSymbol{
+			Name:           closure.Name.Lexeme,
+			Detail:         strings.Join(options, ", "),
+			Kind:           symbolFunction,
+			Range:          rng,
+			SelectionRange: doc.tokenRange(closure.Name),
+		})
+	}
+	return symbols
+}
@@ -16,7 +16,9 @@ type Environment interface {
 type Visitor interface {
 	VisitTaggedClosure(TaggedClosure, Environment) interface{}
 	VisitGetExpr(GetExpr, Environment) interface{}
+	VisitRequestExpr(RequestExpr, Environment) interface{}
 	VisitPrintExpr(PrintExpr, Environment) interface{}
+	VisitEmitExpr(EmitExpr, Environment) interface{}
 	VisitAssignExpr(AssignExpr, Environment) interface{}
 	VisitCallExpr(CallExpr, Environment) interface{}
 	VisitClosureExpr(ClosureExpr, Environment) interface{}
@@ -29,6 +31,16 @@ type Visitor interface {
 	VisitMapAccessExpr(MapAccessExpr, Environment) interface{}
 	VisitReturnExpr(ReturnExpr, Environment) interface{}
 	VisitBodyExpr(BodyExpr, Environment) interface{}
+	VisitBinaryExpr(BinaryExpr, Environment) interface{}
+	VisitUnaryExpr(UnaryExpr, Environment) interface{}
+	VisitGroupingExpr(GroupingExpr, Environment) interface{}
+	VisitIfExpr(IfExpr, Environment) interface{}
+	VisitForExpr(ForExpr, Environment) interface{}
+	VisitWhileExpr(WhileExpr, Environment) interface{}
+	VisitBreakExpr(BreakExpr, Environment) interface{}
+	VisitContinueExpr(ContinueExpr, Environment) interface{}
+	VisitInterpolationExpr(InterpolationExpr, Environment) interface{}
+	VisitTryExpr(TryExpr, Environment) interface{}
 }
 
 // Expr every expression type must implement the expression interface
@@ -38,8 +50,15 @@ type Expr interface {
 
 // TaggedClosure defines a top level closure which can be identifiable by a name
 type TaggedClosure struct {
-	Name *token.Token
-	Body Expr
+	Name    *token.Token
+	Options []TaggedClosureOption
+	Body    Expr
+}
+
+// TaggedClosureOption configures how a tagged closure is executed e.g `max_depth: 3`
+type TaggedClosureOption struct {
+	Name  *token.Token
+	Value Expr
 }
 
 // Accept implements the Expr interface
@@ -54,6 +73,7 @@ func (expr TaggedClosure) String() string {
 // GetExpr use to invoke the http get for the provided url(s)
 type GetExpr struct {
 	Tag    *token.Token
+	Method *token.Token
 	URL    Expr
 	Header Expr
 }
@@ -63,9 +83,24 @@ func (expr GetExpr) Accept(visitor Visitor, env Environment) interface{} {
 	return visitor.VisitGetExpr(expr, env)
 }
 
+// RequestExpr use to invoke the http methods that can send a body e.g post, put for the provided url(s)
+type RequestExpr struct {
+	Tag    *token.Token
+	Method *token.Token
+	URL    Expr
+	Body   Expr
+	Header Expr
+}
+
+// Accept implements the Expr interface
+func (expr RequestExpr) Accept(visitor Visitor, env Environment) interface{} {
+	return visitor.VisitRequestExpr(expr, env)
+}
+
 // PrintExpr prints the provided arguments
 type PrintExpr struct {
-	Args []Expr
+	Keyword *token.Token
+	Args    []Expr
 }
 
 // Accept implements the Expr interface
@@ -73,6 +108,17 @@ func (expr PrintExpr) Accept(visitor Visitor, env Environment) interface{} {
 	return visitor.VisitPrintExpr(expr, env)
 }
 
+// EmitExpr writes a map record to the outputs of the script
+type EmitExpr struct {
+	Keyword *token.Token
+	Record  Expr
+}
+
+// Accept implements the Expr interface
+func (expr EmitExpr) Accept(visitor Visitor, env Environment) interface{} {
+	return visitor.VisitEmitExpr(expr, env)
+}
+
 // AssignExpr assigns an expression result to a variable
 type AssignExpr struct {
 	Name  *token.Token
@@ -86,7 +132,9 @@ func (expr AssignExpr) Accept(visitor Visitor, env Environment) interface{} {
 
 // CallExpr invokes a callable with the provided arguments
 type CallExpr struct {
-	Name      Expr
+	Name Expr
+	// Paren is the opening parenthesis of the argument list. It's nil for free form calls
+	Paren     *token.Token
 	Arguments []Expr
 }
 
@@ -99,6 +147,7 @@ func (expr CallExpr) Accept(visitor Visitor, env Environment) interface{} {
 // at the top level score.
 // This specific closure cannot appear on the top level definition
 type ClosureExpr struct {
+	Paren  *token.Token
 	Params token.Tokens
 	Body   Expr
 }
@@ -121,8 +170,9 @@ func (expr AccessExpr) Accept(visitor Visitor, env Environment) interface{} {
 
 // MapAccessExpr allows accessing an element from a slice by index of map key
 type MapAccessExpr struct {
-	Name Expr
-	Key  Expr
+	Name    Expr
+	Bracket *token.Token
+	Key     Expr
 }
 
 // Accept implements the Expr interface
@@ -143,7 +193,9 @@ func (expr HTMLAttrAccessor) Accept(visitor Visitor, env Environment) interface{
 
 // ArrayExpr initializes an array
 type ArrayExpr struct {
-	Entries []Expr
+	Bracket      *token.Token
+	Entries      []Expr
+	RightBracket *token.Token
 }
 
 // Accept implements the Expr interface
@@ -153,7 +205,9 @@ func (expr ArrayExpr) Accept(visitor Visitor, env Environment) interface{} {
 
 // MapExpr initializes a map
 type MapExpr struct {
-	Entries map[string]Expr
+	Brace      *token.Token
+	Entries    map[string]Expr
+	RightBrace *token.Token
 }
 
 // Accept implements the Expr interface
@@ -183,7 +237,8 @@ func (expr IdentExpr) Accept(visitor Visitor, env Environment) interface{} {
 
 // ReturnExpr causes a function to return at the point of encounter
 type ReturnExpr struct {
-	Value Expr
+	Keyword *token.Token
+	Value   Expr
 }
 
 // Accept implements the Expr interface
@@ -193,10 +248,133 @@ func (expr ReturnExpr) Accept(visitor Visitor, env Environment) interface{} {
 
 // BodyExpr contains all the expressions that are contained within the pair for a set of curly brackets
 type BodyExpr struct {
-	Exprs []Expr
+	Brace      *token.Token
+	Exprs      []Expr
+	RightBrace *token.Token
 }
 
 // Accept implements the Expr interface
 func (expr BodyExpr) Accept(visitor Visitor, env Environment) interface{} {
 	return visitor.VisitBodyExpr(expr, env)
 }
+
+// BinaryExpr applies an arithmetic, comparison or logical operator to two operands
+type BinaryExpr struct {
+	Left     Expr
+	Operator *token.Token
+	Right    Expr
+}
+
+// Accept implements the Expr interface
+func (expr BinaryExpr) Accept(visitor Visitor, env Environment) interface{} {
+	return visitor.VisitBinaryExpr(expr, env)
+}
+
+// UnaryExpr applies either a negation or a logical not to it's operand
+type UnaryExpr struct {
+	Operator *token.Token
+	Right    Expr
+}
+
+// Accept implements the Expr interface
+func (expr UnaryExpr) Accept(visitor Visitor, env Environment) interface{} {
+	return visitor.VisitUnaryExpr(expr, env)
+}
+
+// GroupingExpr is an expression enclosed within parenthesis
+type GroupingExpr struct {
+	Paren *token.Token
+	Expr  Expr
+}
+
+// Accept implements the Expr interface
+func (expr GroupingExpr) Accept(visitor Visitor, env Environment) interface{} {
+	return visitor.VisitGroupingExpr(expr, env)
+}
+
+// IfExpr executes the Then body if the condition is true, otherwise the optional Else branch which
+// is either a body or another IfExpr
+type IfExpr struct {
+	Keyword   *token.Token
+	Condition Expr
+	Then      Expr
+	Else      Expr
+}
+
+// Accept implements the Expr interface
+func (expr IfExpr) Accept(visitor Visitor, env Environment) interface{} {
+	return visitor.VisitIfExpr(expr, env)
+}
+
+// ForExpr executes the body for every entry of an array or map. Key is only set when two loop
+// variables are provided e.g `for key, value in m`
+type ForExpr struct {
+	Keyword  *token.Token
+	Key      *token.Token
+	Value    *token.Token
+	Iterable Expr
+	Body     Expr
+}
+
+// Accept implements the Expr interface
+func (expr ForExpr) Accept(visitor Visitor, env Environment) interface{} {
+	return visitor.VisitForExpr(expr, env)
+}
+
+// WhileExpr executes the body for as long as the condition is true
+type WhileExpr struct {
+	Keyword   *token.Token
+	Condition Expr
+	Body      Expr
+}
+
+// Accept implements the Expr interface
+func (expr WhileExpr) Accept(visitor Visitor, env Environment) interface{} {
+	return visitor.VisitWhileExpr(expr, env)
+}
+
+// TryExpr executes the body and, if it raises a runtime error, the Handler body with the error
+// bound to the Name variable e.g `try { } catch err { }`
+type TryExpr struct {
+	Keyword *token.Token
+	Body    Expr
+	Catch   *token.Token
+	Name    *token.Token
+	Handler Expr
+}
+
+// Accept implements the Expr interface
+func (expr TryExpr) Accept(visitor Visitor, env Environment) interface{} {
+	return visitor.VisitTryExpr(expr, env)
+}
+
+// BreakExpr exits the innermost loop
+type BreakExpr struct {
+	Keyword *token.Token
+}
+
+// Accept implements the Expr interface
+func (expr BreakExpr) Accept(visitor Visitor, env Environment) interface{} {
+	return visitor.VisitBreakExpr(expr, env)
+}
+
+// ContinueExpr skips to the next iteration of the innermost loop
+type ContinueExpr struct {
+	Keyword *token.Token
+}
+
+// Accept implements the Expr interface
+func (expr ContinueExpr) Accept(visitor Visitor, env Environment) interface{} {
+	return visitor.VisitContinueExpr(expr, env)
+}
+
+// InterpolationExpr is a string with embedded expressions. The parts alternate between the string
+// segments, which are literals, and the embedded expressions
+type InterpolationExpr struct {
+	Parts []Expr
+}
+
+// Accept implements the Expr interface
+func (expr InterpolationExpr) Accept(visitor Visitor, env Environment) interface{} {
+	return visitor.VisitInterpolationExpr(expr, env)
+}
@@ -0,0 +1,80 @@
+package parser
+
+import "sort"
+
+// Inspect traverses the expression tree in depth first order, calling f for every expression
+// starting with expr itself. The children of an expression are skipped when f returns false
+func Inspect(expr Expr, f func(Expr) bool) {
+	if expr == nil || !f(expr) {
+		return
+	}
+	for _, child := range children(expr) {
+		Inspect(child, f)
+	}
+}
+
+// children returns the direct child expressions in source order. Map entries are ordered by key
+func children(expr Expr) []Expr {
+	switch e := expr.(type) {
+	case TaggedClosure:
+		var exprs []Expr
+		for _, option := range e.Options {
+			exprs = append(exprs, option.Value)
+		}
+		return append(exprs, e.Body)
+	case GetExpr:
+		return []Expr{e.URL, e.Header}
+	case RequestExpr:
+		return []Expr{e.URL, e.Body, e.Header}
+	case PrintExpr:
+		return e.Args
+	case EmitExpr:
+		return []Expr{e.Record}
+	case AssignExpr:
+		return []Expr{e.Value}
+	case CallExpr:
+		return append([]Expr{e.Name}, e.Arguments...)
+	case ClosureExpr:
+		return []Expr{e.Body}
+	case AccessExpr:
+		return []Expr{e.Var}
+	case MapAccessExpr:
+		return []Expr{e.Name, e.Key}
+	case HTMLAttrAccessor:
+		return []Expr{e.Var}
+	case ArrayExpr:
+		return e.Entries
+	case MapExpr:
+		keys := make([]string, 0, len(e.Entries))
+		for key := range e.Entries {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		exprs := make([]Expr, len(keys))
+		for index, key := range keys {
+			exprs[index] = e.Entries[key]
+		}
+		return exprs
+	case ReturnExpr:
+		return []Expr{e.Value}
+	case BodyExpr:
+		return e.Exprs
+	case BinaryExpr:
+		return []Expr{e.Left, e.Right}
+	case UnaryExpr:
+		return []Expr{e.Right}
+	case GroupingExpr:
+		return []Expr{e.Expr}
+	case IfExpr:
+		return []Expr{e.Condition, e.Then, e.Else}
+	case ForExpr:
+		return []Expr{e.Iterable, e.Body}
+	case WhileExpr:
+		return []Expr{e.Condition, e.Body}
+	case TryExpr:
+		return []Expr{e.Body, e.Handler}
+	case InterpolationExpr:
+		return e.Parts
+	}
+	return nil
+}
@@ -0,0 +1,166 @@
+package parser
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"unicode"
+
+	"github.com/kingzbauer/scraperlang/token"
+)
+
+// Every node is encoded as a JSON object made up of:
+//
+//	1. `kind` the name of the node type e.g "GetExpr"
+//	2. `pos` the 1 based line and column of the left most token of the node, or null
+//	3. The fields of the node in snake case e.g `right_brace`. Tokens are encoded by token.MarshalJSON,
+//	   child nodes as objects, lists of nodes as arrays and map entries as an object keyed by the map key
+//
+// Any JSON object with a `kind` is a node, which allows tools to walk the tree generically
+
+// MarshalJSON implements the json.Marshaler interface
+func (expr TaggedClosure) MarshalJSON() ([]byte, error) { return marshalNode(expr, expr.Name) }
+
+// MarshalJSON implements the json.Marshaler interface
+func (option TaggedClosureOption) MarshalJSON() ([]byte, error) {
+	return marshalNode(option, option.Name)
+}
+
+// MarshalJSON implements the json.Marshaler interface
+func (expr GetExpr) MarshalJSON() ([]byte, error) { return marshalNode(expr, FirstToken(expr)) }
+
+// MarshalJSON implements the json.Marshaler interface
+func (expr RequestExpr) MarshalJSON() ([]byte, error) { return marshalNode(expr, FirstToken(expr)) }
+
+// MarshalJSON implements the json.Marshaler interface
+func (expr PrintExpr) MarshalJSON() ([]byte, error) { return marshalNode(expr, FirstToken(expr)) }
+
+// MarshalJSON implements the json.Marshaler interface
+func (expr EmitExpr) MarshalJSON() ([]byte, error) { return marshalNode(expr, FirstToken(expr)) }
+
+// MarshalJSON implements the json.Marshaler interface
+func (expr AssignExpr) MarshalJSON() ([]byte, error) { return marshalNode(expr, FirstToken(expr)) }
+
+// MarshalJSON implements the json.Marshaler interface
+func (expr CallExpr) MarshalJSON() ([]byte, error) { return marshalNode(expr, FirstToken(expr)) }
+
+// MarshalJSON implements the json.Marshaler interface
+func (expr ClosureExpr) MarshalJSON() ([]byte, error) { return marshalNode(expr, FirstToken(expr)) }
+
+// MarshalJSON implements the json.Marshaler interface
+func (expr AccessExpr) MarshalJSON() ([]byte, error) { return marshalNode(expr, FirstToken(expr)) }
+
+// MarshalJSON implements the json.Marshaler interface
+func (expr MapAccessExpr) MarshalJSON() ([]byte, error) { return marshalNode(expr, FirstToken(expr)) }
+
+// MarshalJSON implements the json.Marshaler interface
+func (expr HTMLAttrAccessor) MarshalJSON() ([]byte, error) {
+	return marshalNode(expr, FirstToken(expr))
+}
+
+// MarshalJSON implements the json.Marshaler interface
+func (expr ArrayExpr) MarshalJSON() ([]byte, error) { return marshalNode(expr, FirstToken(expr)) }
+
+// MarshalJSON implements the json.Marshaler interface
+func (expr MapExpr) MarshalJSON() ([]byte, error) { return marshalNode(expr, FirstToken(expr)) }
+
+// MarshalJSON implements the json.Marshaler interface
+func (expr LiteralExpr) MarshalJSON() ([]byte, error) { return marshalNode(expr, FirstToken(expr)) }
+
+// MarshalJSON implements the json.Marshaler interface
+func (expr IdentExpr) MarshalJSON() ([]byte, error) { return marshalNode(expr, FirstToken(expr)) }
+
+// MarshalJSON implements the json.Marshaler interface
+func (expr ReturnExpr) MarshalJSON() ([]byte, error) { return marshalNode(expr, FirstToken(expr)) }
+
+// MarshalJSON implements the json.Marshaler interface
+func (expr BodyExpr) MarshalJSON() ([]byte, error) { return marshalNode(expr, FirstToken(expr)) }
+
+// MarshalJSON implements the json.Marshaler interface
+func (expr BinaryExpr) MarshalJSON() ([]byte, error) { return marshalNode(expr, FirstToken(expr)) }
+
+// MarshalJSON implements the json.Marshaler interface
+func (expr UnaryExpr) MarshalJSON() ([]byte, error) { return marshalNode(expr, FirstToken(expr)) }
+
+// MarshalJSON implements the json.Marshaler interface
+func (expr GroupingExpr) MarshalJSON() ([]byte, error) { return marshalNode(expr, FirstToken(expr)) }
+
+// MarshalJSON implements the json.Marshaler interface
+func (expr IfExpr) MarshalJSON() ([]byte, error) { return marshalNode(expr, FirstToken(expr)) }
+
+// MarshalJSON implements the json.Marshaler interface
+func (expr ForExpr) MarshalJSON() ([]byte, error) { return marshalNode(expr, FirstToken(expr)) }
+
+// MarshalJSON implements the json.Marshaler interface
+func (expr WhileExpr) MarshalJSON() ([]byte, error) { return marshalNode(expr, FirstToken(expr)) }
+
+// MarshalJSON implements the json.Marshaler interface
+func (expr TryExpr) MarshalJSON() ([]byte, error) { return marshalNode(expr, FirstToken(expr)) }
+
+// MarshalJSON implements the json.Marshaler interface
+func (expr BreakExpr) MarshalJSON() ([]byte, error) { return marshalNode(expr, FirstToken(expr)) }
+
+// MarshalJSON implements the json.Marshaler interface
+func (expr ContinueExpr) MarshalJSON() ([]byte, error) { return marshalNode(expr, FirstToken(expr)) }
+
+// MarshalJSON implements the json.Marshaler interface
+func (expr InterpolationExpr) MarshalJSON() ([]byte, error) {
+	return marshalNode(expr, FirstToken(expr))
+}
+
+// position is the 1 based position of a node
+type position struct {
+	Line   int `json:"line"`
+	Column int `json:"column"`
+}
+
+// marshalNode encodes the kind, position and fields of the node. The fields are encoded in the
+// order they are declared in
+func marshalNode(node interface{}, first *token.Token) ([]byte, error) {
+	value := reflect.ValueOf(node)
+	buf := &bytes.Buffer{}
+	buf.WriteString(`{"kind":`)
+	kind, _ := json.Marshal(value.Type().Name())
+	buf.Write(kind)
+
+	var pos *position
+	if first != nil {
+		pos = &position{Line: first.Line + 1, Column: first.Column + 1}
+	}
+	if err := writeField(buf, "pos", pos); err != nil {
+		return nil, err
+	}
+	for index := 0; index < value.NumField(); index++ {
+		if err := writeField(buf, snakeCase(value.Type().Field(index).Name), value.Field(index).Interface()); err != nil {
+			return nil, err
+		}
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+func writeField(buf *bytes.Buffer, name string, value interface{}) error {
+	content, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	buf.WriteString(`,"` + name + `":`)
+	buf.Write(content)
+	return nil
+}
+
+// snakeCase converts a field name e.g RightBrace into right_brace
+func snakeCase(name string) string {
+	buf := &strings.Builder{}
+	for index, r := range name {
+		if unicode.IsUpper(r) {
+			if index > 0 && !unicode.IsUpper(rune(name[index-1])) {
+				buf.WriteByte('_')
+			}
+			r = unicode.ToLower(r)
+		}
+		buf.WriteRune(r)
+	}
+	return buf.String()
+}
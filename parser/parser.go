@@ -3,6 +3,7 @@ package parser
 import (
 	"fmt"
 
+	"github.com/kingzbauer/scraperlang/diag"
 	"github.com/kingzbauer/scraperlang/token"
 )
 
@@ -13,11 +14,12 @@ type Error struct {
 }
 
 func (err Error) Error() string {
-	prefix := ""
-	if err.token != nil {
-		prefix = fmt.Sprintf("[%d:%d]", err.token.Line+1, err.token.Column)
-	}
-	return fmt.Sprintf("%s %s", prefix, err.msg)
+	return err.Diagnostic().Error()
+}
+
+// Diagnostic implements the diag.Diagnoser interface
+func (err Error) Diagnostic() diag.Diagnostic {
+	return err.token.Diagnostic(diag.Error, err.msg)
 }
 
 // Parser builds an AST from the provided tokens
@@ -25,11 +27,21 @@ type Parser struct {
 	tokens  token.Tokens
 	current int
 	errs    []error
+	// inCondition is set while parsing the condition of a statement that is followed by a body
+	// e.g if, for. It prevents the '{' that starts the body from being parsed as a map argument of a
+	// call expression without parenthesis
+	inCondition bool
 }
 
-// New creates and returns a new parser
+// New creates and returns a new parser. Comment tokens are ignored
 func New(tokens token.Tokens) *Parser {
-	return &Parser{tokens: tokens}
+	filtered := make(token.Tokens, 0, len(tokens))
+	for _, t := range tokens {
+		if t.Type != token.Comment {
+			filtered = append(filtered, t)
+		}
+	}
+	return &Parser{tokens: filtered}
 }
 
 // Err returns any error if present
@@ -47,7 +59,8 @@ func (p *Parser) addErr(err error) {
 }
 
 // Parse processes the tokens and returns an AST which is basically
-// a list of expression trees
+// a list of expression trees. Syntax errors don't stop the parse, they are recorded and can be
+// retrieved with Err once parsing completes
 func (p *Parser) Parse() (ast []Expr, err error) {
 	defer func() {
 		if val := recover(); val != nil {
@@ -60,22 +73,106 @@ func (p *Parser) Parse() (ast []Expr, err error) {
 	return
 }
 
+// ParseStatements parses a sequence of statements, as found in the body of a closure, up to the end
+// of the tokens. It allows evaluating statements outside of a tagged closure e.g by a REPL. Syntax
+// errors are recorded the same way as Parse
+func (p *Parser) ParseStatements() (stmts []Expr, err error) {
+	defer func() {
+		if val := recover(); val != nil {
+			if e, isError := val.(error); isError {
+				err = e
+			}
+		}
+	}()
+
+	p.eatAll(token.Newline)
+	for !p.check(token.EOF) {
+		// A closing curly bracket can't be skipped by synchronize since it ends a body
+		if p.check(token.RightCurlyBracket) {
+			p.addErr(Error{token: p.advance(), msg: "Unexpected '}'"})
+			continue
+		}
+		if stmt := p.synchronizedStatement(); stmt != nil {
+			stmts = append(stmts, stmt)
+		}
+	}
+	return
+}
+
+// ParseExpression parses a single expression that spans all the tokens, ignoring any trailing
+// newlines
+func (p *Parser) ParseExpression() (expr Expr, err error) {
+	defer func() {
+		if val := recover(); val != nil {
+			if e, isError := val.(error); isError {
+				err = e
+			}
+		}
+	}()
+
+	p.eatAll(token.Newline)
+	expr = p.expression()
+	p.eatAll(token.Newline)
+	if !p.check(token.EOF) {
+		panic(Error{token: p.peek(), msg: "Expect the end of the expression"})
+	}
+	return
+}
+
 func (p *Parser) globalDefs() []Expr {
 	exprs := []Expr{}
 	for !p.match(token.EOF) {
-		closure := p.taggledClosure()
-		exprs = append(exprs, closure)
+		if closure := p.synchronizedTaggedClosure(); closure != nil {
+			exprs = append(exprs, closure)
+		}
 		p.eatAll(token.Newline)
 	}
 
 	return exprs
 }
 
+// synchronizedTaggedClosure parses a tagged closure. On a syntax error that could not be recovered
+// from within the closure body, the error is recorded and the parser skips ahead to the next tagged
+// closure
+func (p *Parser) synchronizedTaggedClosure() (expr Expr) {
+	defer func() {
+		if val := recover(); val != nil {
+			err, ok := val.(Error)
+			if !ok {
+				panic(val)
+			}
+			p.addErr(err)
+			p.synchronizeTaggedClosure()
+			expr = nil
+		}
+	}()
+
+	return p.taggledClosure()
+}
+
+// synchronizeTaggedClosure discards tokens up to the start of the next tagged closure, that is an
+// identifier at the start of a line that is followed by either '{' or '('
+func (p *Parser) synchronizeTaggedClosure() {
+	for !p.check(token.EOF) {
+		p.eatUntil(token.Newline)
+		p.eatAll(token.Newline)
+		t := p.peek()
+		if t.Type == token.Ident && t.Column == 0 && p.current+1 < len(p.tokens) {
+			if next := p.tokens[p.current+1]; next.Type == token.LeftCurlyBracket || next.Type == token.LeftParen {
+				return
+			}
+		}
+	}
+}
+
 func (p *Parser) taggledClosure() Expr {
 	taggedClosure := TaggedClosure{}
 
 	p.eatAll(token.Newline)
 	closureName := p.consume("Expected a tagged closure", token.Ident)
+	if p.match(token.LeftParen) {
+		taggedClosure.Options = p.taggedClosureOptions()
+	}
 	p.consume("Expected '{' to start the closure body", token.LeftCurlyBracket)
 
 	taggedClosure.Name = closureName
@@ -84,84 +181,266 @@ func (p *Parser) taggledClosure() Expr {
 	return taggedClosure
 }
 
+func (p *Parser) taggedClosureOptions() []TaggedClosureOption {
+	var options []TaggedClosureOption
+	p.eatAll(token.Newline)
+	if !p.check(token.RightParen) {
+		options = append(options, p.taggedClosureOption())
+		for p.match(token.Comma) {
+			p.eatAll(token.Newline)
+			options = append(options, p.taggedClosureOption())
+		}
+		p.eatAll(token.Newline)
+	}
+	p.consume("Expect ')' to close the tagged closure options", token.RightParen)
+	return options
+}
+
+func (p *Parser) taggedClosureOption() TaggedClosureOption {
+	name := p.consume("Expect a tagged closure option name", token.Ident)
+	p.consume("Expect ':' after the option name", token.Colon)
+	return TaggedClosureOption{Name: name, Value: p.expression()}
+}
+
 func (p *Parser) body() Expr {
 	var exprs []Expr
+	// The opening curly bracket is consumed by the caller
+	brace := p.previous()
+
+	inCondition := p.inCondition
+	p.inCondition = false
+	defer func() {
+		p.inCondition = inCondition
+	}()
 
 	p.eatAll(token.Newline)
 
 	for !p.check(token.RightCurlyBracket, token.EOF) {
-		t := p.advance()
-		switch t.Type {
-		case token.Tag:
-			p.consume("Expect a get expression after a tag", token.Get)
-			exprs = append(exprs, p.getExpr(t))
-		case token.Get:
-			exprs = append(exprs, p.getExpr())
-		case token.Print:
-			exprs = append(exprs, p.printExpr())
-		case token.Return:
-			var expr Expr
-			// If the next token is neither a Newline or Closing bracket, we expect an expression
-			if !p.check(token.Newline, token.RightCurlyBracket) {
-				expr = p.expression()
+		if stmt := p.synchronizedStatement(); stmt != nil {
+			exprs = append(exprs, stmt)
+		}
+	}
+
+	rightBrace := p.consume("Expect '}' to close body", token.RightCurlyBracket)
+	return BodyExpr{Brace: brace, Exprs: exprs, RightBrace: rightBrace}
+}
+
+// synchronizedStatement parses a statement and the newlines that terminate it. On a syntax error, the
+// error is recorded and the parser skips ahead to the next statement so that parsing can continue
+func (p *Parser) synchronizedStatement() (stmt Expr) {
+	defer func() {
+		if val := recover(); val != nil {
+			err, ok := val.(Error)
+			if !ok {
+				panic(val)
 			}
-			exprs = append(exprs, ReturnExpr{Value: expr})
-		case token.Ident:
-			if p.match(token.Equal) {
-				// Process an assignment
-				exprs = append(exprs, p.assignExpr(t))
-			} else if p.match(token.LeftParen) {
-				// Process a call expression
-				argList := p.expressionList(token.RightParen)
-				exprs = append(exprs, CallExpr{Name: LiteralExpr{Value: t}, Arguments: argList})
-				p.consume("Call expression requires a closing ')'", token.RightParen)
-			} else if p.match(token.Period) {
-				// parse an attribute function call
-				field := p.consume("Expect an field accessor after '.'", token.Ident)
-				var argList []Expr
-				if p.match(token.LeftParen) {
-					argList = p.expressionList(token.RightParen)
-					p.consume("Expect ')' to close functin call", token.RightParen)
-				} else {
-					// This is a free form function call which needs at least one argument
-					argList = p.expressionList()
-				}
-				accessExpr := AccessExpr{Var: LiteralExpr{Value: t}, Field: field}
-				exprs = append(exprs, CallExpr{Name: accessExpr, Arguments: argList})
-			} else if p.check(token.Newline) {
-				p.addErr(Error{
-					msg:   "call expression without parenthesis requires at least one argument",
-					token: p.previous(),
-				})
-				exprs = append(exprs, CallExpr{Name: LiteralExpr{Value: t}})
+			p.addErr(err)
+			p.synchronize()
+			stmt = nil
+		}
+	}()
+
+	stmt = p.statement()
+	if !p.check(token.RightCurlyBracket) {
+		// Consume a Newline after each expression statement
+		p.consume("Expect a 'Newline'", token.Newline)
+		p.eatAll(token.Newline)
+	}
+	return stmt
+}
+
+// synchronize discards tokens up to the end of the current statement. That is either the next
+// newline or the '}' closing the enclosing body, ignoring any that are nested within brackets.
+// Parenthesis and square brackets left open don't extend past the '}' closing the enclosing body
+func (p *Parser) synchronize() {
+	var open []token.Type
+	// closeTo pops the brackets opened after the innermost bracket of the type, reporting whether
+	// there was one
+	closeTo := func(typ token.Type) bool {
+		for index := len(open) - 1; index >= 0; index-- {
+			if open[index] == typ {
+				open = open[:index]
+				return true
+			}
+		}
+		return false
+	}
+	for !p.check(token.EOF) {
+		switch p.peek().Type {
+		case token.LeftCurlyBracket, token.LeftParen, token.LeftBracket:
+			open = append(open, p.peek().Type)
+		case token.RightParen:
+			closeTo(token.LeftParen)
+		case token.RightBracket:
+			closeTo(token.LeftBracket)
+		case token.RightCurlyBracket:
+			if !closeTo(token.LeftCurlyBracket) {
+				return
+			}
+		case token.Newline:
+			if len(open) == 0 {
+				p.eatAll(token.Newline)
+				return
+			}
+		}
+		p.advance()
+	}
+}
+
+func (p *Parser) statement() Expr {
+	t := p.advance()
+	switch t.Type {
+	case token.Tag:
+		method := p.consume("Expect a request expression after a tag", token.Get, token.Post, token.Put,
+			token.Patch, token.Delete, token.Head)
+		if method.Type == token.Get {
+			return p.getExpr(method, t)
+		}
+		return p.requestExpr(method, t)
+	case token.Get:
+		return p.getExpr(t)
+	case token.Post, token.Put, token.Patch, token.Delete, token.Head:
+		return p.requestExpr(t)
+	case token.Print:
+		return p.printExpr(t)
+	case token.Emit:
+		return EmitExpr{Keyword: t, Record: p.expression()}
+	case token.If:
+		return p.ifExpr(t)
+	case token.For:
+		return p.forExpr(t)
+	case token.Try:
+		return p.tryExpr(t)
+	case token.While:
+		condition := p.condition()
+		p.consume("Expect '{' after the while condition", token.LeftCurlyBracket)
+		return WhileExpr{Keyword: t, Condition: condition, Body: p.body()}
+	case token.Break:
+		return BreakExpr{Keyword: t}
+	case token.Continue:
+		return ContinueExpr{Keyword: t}
+	case token.Return:
+		var expr Expr
+		// If the next token is neither a Newline or Closing bracket, we expect an expression
+		if !p.check(token.Newline, token.RightCurlyBracket) {
+			expr = p.expression()
+		}
+		return ReturnExpr{Keyword: t, Value: expr}
+	case token.Ident:
+		if p.match(token.Equal) {
+			// Process an assignment
+			return p.assignExpr(t)
+		} else if p.match(token.LeftParen) {
+			// Process a call expression
+			paren := p.previous()
+			argList := p.expressionList(token.RightParen)
+			p.consume("Call expression requires a closing ')'", token.RightParen)
+			return CallExpr{Name: LiteralExpr{Value: t}, Paren: paren, Arguments: argList}
+		} else if p.match(token.Period) {
+			// parse an attribute function call
+			field := p.consume("Expect an field accessor after '.'", token.Ident)
+			var argList []Expr
+			var paren *token.Token
+			if p.match(token.LeftParen) {
+				paren = p.previous()
+				argList = p.expressionList(token.RightParen)
+				p.consume("Expect ')' to close functin call", token.RightParen)
 			} else {
-				// This should ideally be a call expression without the parenthesis
-				// Requires at least one expression
-				argList := p.expressionList()
-				if len(argList) == 0 {
-					p.addErr(Error{
-						msg:   fmt.Sprintf("If this is a function, it requires at least one argument"),
-						token: t,
-					})
-				}
-				exprs = append(exprs, CallExpr{Name: LiteralExpr{Value: t}, Arguments: argList})
+				// This is a free form function call which needs at least one argument
+				argList = p.expressionList()
 			}
-		default:
-			panic(Error{
-				token: t,
-				msg:   "Invalid expression statement as a top-level statement",
+			accessExpr := AccessExpr{Var: LiteralExpr{Value: t}, Field: field}
+			return CallExpr{Name: accessExpr, Paren: paren, Arguments: argList}
+		} else if p.check(token.Newline) {
+			p.addErr(Error{
+				msg:   "call expression without parenthesis requires at least one argument",
+				token: p.previous(),
 			})
+			return CallExpr{Name: LiteralExpr{Value: t}}
+		} else {
+			// This should ideally be a call expression without the parenthesis
+			// Requires at least one expression
+			argList := p.expressionList()
+			if len(argList) == 0 {
+				p.addErr(Error{
+					msg:   fmt.Sprintf("If this is a function, it requires at least one argument"),
+					token: t,
+				})
+			}
+			return CallExpr{Name: LiteralExpr{Value: t}, Arguments: argList}
 		}
+	default:
+		panic(Error{
+			token: t,
+			msg:   "Invalid expression statement as a top-level statement",
+		})
+	}
+}
 
-		if !p.check(token.RightCurlyBracket) {
-			// Consume a Newline after each expression statement
-			p.consume("Expect a 'Newline'", token.Newline)
-			p.eatAll(token.Newline)
+func (p *Parser) ifExpr(keyword *token.Token) Expr {
+	expr := IfExpr{Keyword: keyword}
+	expr.Condition = p.condition()
+	p.consume("Expect '{' after the if condition", token.LeftCurlyBracket)
+	expr.Then = p.body()
+	if p.match(token.Else) {
+		if p.match(token.If) {
+			expr.Else = p.ifExpr(p.previous())
+		} else {
+			p.consume("Expect '{' or 'if' after else", token.LeftCurlyBracket)
+			expr.Else = p.body()
 		}
 	}
+	return expr
+}
 
-	p.consume("Expect '}' to close body", token.RightCurlyBracket)
-	return BodyExpr{Exprs: exprs}
+func (p *Parser) forExpr(keyword *token.Token) Expr {
+	expr := ForExpr{Keyword: keyword}
+	expr.Value = p.consume("Expect a loop variable after 'for'", token.Ident)
+	if p.match(token.Comma) {
+		expr.Key = expr.Value
+		expr.Value = p.consume("Expect a second loop variable after ','", token.Ident)
+		if expr.Key.Lexeme == expr.Value.Lexeme {
+			p.addErr(Error{
+				msg:   fmt.Sprintf("duplicate loop variable %q", expr.Value.Lexeme),
+				token: expr.Value,
+			})
+		}
+	}
+	p.consume("Expect 'in' after the loop variables", token.In)
+	expr.Iterable = p.condition()
+	p.consume("Expect '{' after the for iterable", token.LeftCurlyBracket)
+	expr.Body = p.body()
+	return expr
+}
+
+func (p *Parser) tryExpr(keyword *token.Token) Expr {
+	expr := TryExpr{Keyword: keyword}
+	p.consume("Expect '{' after 'try'", token.LeftCurlyBracket)
+	expr.Body = p.body()
+	expr.Catch = p.consume("Expect 'catch' after the try body", token.Catch)
+	expr.Name = p.consume("Expect an error variable after 'catch'", token.Ident)
+	p.consume("Expect '{' after the error variable", token.LeftCurlyBracket)
+	expr.Handler = p.body()
+	return expr
+}
+
+// enclose clears inCondition while parsing within parenthesis, where a '{' can't start the body of
+// the statement e.g a closure passed as an argument. The returned function restores it
+func (p *Parser) enclose() (restore func()) {
+	inCondition := p.inCondition
+	p.inCondition = false
+	return func() {
+		p.inCondition = inCondition
+	}
+}
+
+// condition parses the expression that precedes the body of a statement e.g if
+func (p *Parser) condition() Expr {
+	p.inCondition = true
+	defer func() {
+		p.inCondition = false
+	}()
+	return p.expression()
 }
 
 func (p *Parser) assignExpr(t *token.Token) Expr {
@@ -169,8 +448,8 @@ func (p *Parser) assignExpr(t *token.Token) Expr {
 	return AssignExpr{Name: t, Value: value}
 }
 
-func (p *Parser) getExpr(tag ...*token.Token) Expr {
-	expr := GetExpr{}
+func (p *Parser) getExpr(method *token.Token, tag ...*token.Token) Expr {
+	expr := GetExpr{Method: method}
 	if len(tag) > 0 {
 		expr.Tag = tag[0]
 	}
@@ -179,21 +458,39 @@ func (p *Parser) getExpr(tag ...*token.Token) Expr {
 	URL := p.expression()
 	expr.URL = URL
 
-	// We expect an optional header argument and then a newline to complete the statement
-	if !p.check(token.Newline) {
-		p.consume("Expect ','", token.Comma)
-		httpHeaderExpr := p.expression()
-		expr.Header = httpHeaderExpr
+	// Followed by an optional header argument
+	if p.match(token.Comma) {
+		expr.Header = p.expression()
 	}
 
 	return expr
 }
 
-func (p *Parser) printExpr() Expr {
+func (p *Parser) requestExpr(method *token.Token, tag ...*token.Token) Expr {
+	expr := RequestExpr{Method: method}
+	if len(tag) > 0 {
+		expr.Tag = tag[0]
+	}
+
+	// We expect at least a single expression as the first argument
+	expr.URL = p.expression()
+
+	// Followed by an optional body argument and an optional header argument
+	if p.match(token.Comma) {
+		expr.Body = p.expression()
+		if p.match(token.Comma) {
+			expr.Header = p.expression()
+		}
+	}
+
+	return expr
+}
+
+func (p *Parser) printExpr(keyword *token.Token) Expr {
 	// We might want to catch any error thrown when parsing the expressions parsed to print statement
 	// to give a more meaningful, for now we just allow the normal panic handling at the toplevel parse
 	// function
-	expr := PrintExpr{}
+	expr := PrintExpr{Keyword: keyword}
 	// We expect at least one expression
 	args := []Expr{p.expression()}
 	for p.match(token.Comma) {
@@ -206,21 +503,97 @@ func (p *Parser) printExpr() Expr {
 }
 
 func (p *Parser) expression() Expr {
-	expr := p.htmlAttrAccessor()
+	expr := p.or()
 	return expr
 }
 
+// binary parses a left associative binary expression made up of operands parsed by the operand
+// function and separated by any of the operators
+func (p *Parser) binary(operand func() Expr, operators ...token.Type) Expr {
+	expr := operand()
+	for p.match(operators...) {
+		operator := p.previous()
+		// An operator can be followed by a newline to continue the expression on the next line
+		p.eatAll(token.Newline)
+		right := operand()
+		expr = BinaryExpr{Left: expr, Operator: operator, Right: right}
+	}
+	return expr
+}
+
+func (p *Parser) or() Expr {
+	return p.binary(p.and, token.Or)
+}
+
+func (p *Parser) and() Expr {
+	return p.binary(p.not, token.And)
+}
+
+// not parses a logical negation, which binds looser than the comparisons it negates e.g
+// `not a == b` is `not (a == b)`
+func (p *Parser) not() Expr {
+	if p.match(token.Not) {
+		operator := p.previous()
+		right := p.not()
+		return UnaryExpr{Operator: operator, Right: right}
+	}
+	return p.equality()
+}
+
+func (p *Parser) equality() Expr {
+	return p.binary(p.comparison, token.EqualEqual, token.BangEqual)
+}
+
+func (p *Parser) comparison() Expr {
+	return p.binary(p.term, token.Less, token.LessEqual, token.Greater, token.GreaterEqual)
+}
+
+func (p *Parser) term() Expr {
+	return p.binary(p.factor, token.Plus, token.Minus)
+}
+
+func (p *Parser) factor() Expr {
+	return p.binary(p.unary, token.Star, token.Slash, token.Percent)
+}
+
+func (p *Parser) unary() Expr {
+	if p.match(token.Minus) {
+		operator := p.previous()
+		right := p.unary()
+		return UnaryExpr{Operator: operator, Right: right}
+	}
+	return p.htmlAttrAccessor()
+}
+
 func (p *Parser) htmlAttrAccessor() Expr {
 	expr := p.accessor()
 	if p.match(token.Tilde) {
 		attr := p.consume("HTML attribute identifier expected", token.Ident)
 		return HTMLAttrAccessor{Var: expr, Attr: attr}
+	} else if p.inCondition && p.check(token.LeftCurlyBracket) {
+		return expr
 	} else if !p.check(
 		token.Newline,
 		token.Comma,
 		token.RightBracket,
 		token.RightCurlyBracket,
-		token.RightParen) {
+		token.RightParen,
+		token.EOF,
+		token.Plus,
+		token.Minus,
+		token.Star,
+		token.Slash,
+		token.Percent,
+		token.EqualEqual,
+		token.BangEqual,
+		token.Less,
+		token.LessEqual,
+		token.Greater,
+		token.GreaterEqual,
+		token.And,
+		token.Or,
+		token.InterpolationPart,
+		token.InterpolationEnd) {
 		// We need to get an argument list
 		argList := p.expressionList()
 		expr = CallExpr{Name: expr, Arguments: argList}
@@ -230,27 +603,27 @@ func (p *Parser) htmlAttrAccessor() Expr {
 
 // TODO: Rename this
 func (p *Parser) accessor() Expr {
+	if p.check(token.LeftParen) && p.isClosure() {
+		return p.closure(p.advance())
+	}
 	switch p.peek().Type {
-	case token.LeftParen:
-		p.advance()
-		return p.closure()
 	case token.LeftBracket:
-		p.advance()
-		return p.arrayExpr()
+		return p.arrayExpr(p.advance())
 	case token.LeftCurlyBracket:
-		p.advance()
-		return p.mapExpr()
+		return p.mapExpr(p.advance())
 	default:
 		expr := p.primary()
 		for {
 			switch p.peek().Type {
 			case token.LeftParen:
-				p.advance()
+				paren := p.advance()
+				restore := p.enclose()
 				arguments := p.expressionList(token.RightParen)
+				restore()
 				p.consume("Call expression requires a closing ')'", token.RightParen)
-				expr = CallExpr{Name: expr, Arguments: arguments}
+				expr = CallExpr{Name: expr, Paren: paren, Arguments: arguments}
 			case token.LeftBracket:
-				p.advance()
+				bracket := p.advance()
 				if p.peek().Type == token.RightBracket {
 					panic(Error{
 						token: p.advance(),
@@ -259,7 +632,7 @@ func (p *Parser) accessor() Expr {
 				}
 				key := p.expression()
 				p.consume("Expected ']'", token.RightBracket)
-				expr = MapAccessExpr{Name: expr, Key: key}
+				expr = MapAccessExpr{Name: expr, Bracket: bracket, Key: key}
 			case token.Period:
 				p.advance()
 				ident := p.consume("Expect an attribute name after a '.'", token.Ident)
@@ -304,7 +677,7 @@ func (p *Parser) expressionList(delimiter ...token.Type) []Expr {
 	return exprs
 }
 
-func (p *Parser) mapExpr() Expr {
+func (p *Parser) mapExpr(brace *token.Token) Expr {
 	// Consume any newlines if any
 	p.eatAll(token.Newline)
 	entries := make(map[string]Expr)
@@ -319,8 +692,8 @@ func (p *Parser) mapExpr() Expr {
 		}
 		p.eatAll(token.Newline)
 	}
-	p.consume("expect closing '}'", token.RightCurlyBracket)
-	return MapExpr{Entries: entries}
+	rightBrace := p.consume("expect closing '}'", token.RightCurlyBracket)
+	return MapExpr{Brace: brace, Entries: entries, RightBrace: rightBrace}
 }
 
 func (p *Parser) mapEntry() (*token.Token, Expr) {
@@ -331,7 +704,7 @@ func (p *Parser) mapEntry() (*token.Token, Expr) {
 	return key, value
 }
 
-func (p *Parser) arrayExpr() Expr {
+func (p *Parser) arrayExpr(bracket *token.Token) Expr {
 	exprs := []Expr{}
 	// Consume all possible newlines after the opening square bracket
 	p.eatAll(token.Newline)
@@ -346,11 +719,11 @@ func (p *Parser) arrayExpr() Expr {
 		p.eatAll(token.Newline)
 	}
 	// All expressions have been consumed to this point, we therefore expect a closing Right Bracket
-	p.consume("expect ']'", token.RightBracket)
-	return ArrayExpr{Entries: exprs}
+	rightBracket := p.consume("expect ']'", token.RightBracket)
+	return ArrayExpr{Bracket: bracket, Entries: exprs, RightBracket: rightBracket}
 }
 
-func (p *Parser) closure() Expr {
+func (p *Parser) closure(paren *token.Token) Expr {
 	// parameter list
 	// If the next token is not a closing paren, we expect a parameter list
 	paramList := token.Tokens{}
@@ -380,7 +753,7 @@ func (p *Parser) closure() Expr {
 	p.consume("A closure requires a body", token.RightParen)
 	p.consume("Missing '{' to start the closure body", token.LeftCurlyBracket)
 	body := p.body()
-	return ClosureExpr{Params: paramList, Body: body}
+	return ClosureExpr{Paren: paren, Params: paramList, Body: body}
 }
 
 func (p *Parser) primary() Expr {
@@ -398,14 +771,57 @@ func (p *Parser) primary() Expr {
 		return LiteralExpr{t}
 	case token.Ident:
 		return IdentExpr{t}
+	case token.InterpolationStart:
+		parts := []Expr{LiteralExpr{t}}
+		for {
+			parts = append(parts, p.expression())
+			part := p.consume("Expect '}' to close the interpolated expression",
+				token.InterpolationPart, token.InterpolationEnd)
+			parts = append(parts, LiteralExpr{part})
+			if part.Type == token.InterpolationEnd {
+				return InterpolationExpr{Parts: parts}
+			}
+		}
+	case token.LeftParen:
+		restore := p.enclose()
+		p.eatAll(token.Newline)
+		expr := p.expression()
+		p.eatAll(token.Newline)
+		restore()
+		p.consume("Expect ')' after expression", token.RightParen)
+		return GroupingExpr{Paren: t, Expr: expr}
 	default:
+		// Newlines, '}' and EOF end the statement, they are left for synchronize to stop at
+		if t.Type == token.Newline || t.Type == token.RightCurlyBracket || t.Type == token.EOF {
+			p.current--
+		}
 		panic(Error{
 			token: t,
-			msg:   "Unexpected token",
+			msg:   fmt.Sprintf("Unexpected %s", describe(t)),
 		})
 	}
 }
 
+// isClosure looks ahead to determine whether the '(' at the current position starts the parameter
+// list of a closure rather than a grouping expression. Within a condition, the '{' following the
+// parenthesis starts the body of the statement e.g `if (found) {`
+func (p *Parser) isClosure() bool {
+	if p.inCondition {
+		return false
+	}
+	for index := p.current + 1; index < len(p.tokens); index++ {
+		switch p.tokens[index].Type {
+		case token.Ident, token.Comma, token.Newline:
+			continue
+		case token.RightParen:
+			return index+1 < len(p.tokens) && p.tokens[index+1].Type == token.LeftCurlyBracket
+		default:
+			return false
+		}
+	}
+	return false
+}
+
 func (p *Parser) isAtEnd() bool {
 	return p.current >= len(p.tokens)
 }
@@ -460,12 +876,22 @@ func (p *Parser) consume(msg string, typ ...token.Type) *token.Token {
 		t := p.peek()
 		panic(Error{
 			token: t,
-			msg:   fmt.Sprintf("%s. Got unexpected %s", msg, t),
+			msg:   fmt.Sprintf("%s. Got unexpected %s", msg, describe(t)),
 		})
 	}
 	return p.previous()
 }
 
+// describe names the kind of the token for syntax errors, followed by it's lexeme unless the token
+// has no visible lexeme e.g a newline
+func describe(t *token.Token) string {
+	switch t.Type {
+	case token.Newline, token.EOF:
+		return t.Type.String()
+	}
+	return fmt.Sprintf("%s '%s'", t.Type, t.Lexeme)
+}
+
 // eatAll consumes all consequetive tokens that match the provided type and stops
 // when they find a token of a different type
 func (p *Parser) eatAll(typ token.Type) {
@@ -474,8 +900,9 @@ func (p *Parser) eatAll(typ token.Type) {
 	}
 }
 
+// eatUntil consumes all tokens up to the first token of the provided type or EOF
 func (p *Parser) eatUntil(typ token.Type) {
-	for !p.check(typ) && !p.isAtEnd() {
+	for !p.check(typ, token.EOF) {
 		p.advance()
 	}
 }
@@ -0,0 +1,202 @@
+package parser
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/kingzbauer/scraperlang/token"
+)
+
+// parseErrs parses the source and returns it's syntax errors as 1-based "line:column: message"
+func parseErrs(t *testing.T, src string) []string {
+	t.Helper()
+	tokens, err := token.NewScanner([]byte(src)).ScanTokens()
+	if err != nil {
+		t.Fatalf("scanning the source: %s", err)
+	}
+	p := New(tokens)
+	if _, err := p.Parse(); err != nil {
+		t.Fatalf("parsing the source: %s", err)
+	}
+	var errs []string
+	for _, err := range p.Err() {
+		d := err.(Error).Diagnostic()
+		errs = append(errs, fmt.Sprintf("%d:%d: %s", d.Start.Line+1, d.Start.Column+1, d.Msg))
+	}
+	return errs
+}
+
+func TestParseRecovery(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		errs []string
+	}{
+		{
+			name: "no errors",
+			src:  "init {\n  a = 1\n}\n",
+		},
+		{
+			name: "errors on consecutive statements",
+			src:  "init {\n  a =\n  b = [1, 2\n  c = 3\n  d = )\n}\n",
+			errs: []string{
+				"2:6: Unexpected Newline",
+				"4:3: expect ']'. Got unexpected Ident 'c'",
+				"5:7: Unexpected RightParen ')'",
+			},
+		},
+		{
+			name: "the closing bracket ends the body",
+			src:  "init {\n  a = (\n}\n\npage {\n  x = {'a': 1}\n  @x get 'http://a'\n  c = ]\n}\n",
+			errs: []string{
+				"3:1: Unexpected RightCurlyBracket '}'",
+				"8:7: Unexpected RightBracket ']'",
+			},
+		},
+		{
+			name: "errors in nested bodies",
+			src:  "init {\n  if a {\n    b = *\n  }\n  c = 1 +\n}\n",
+			errs: []string{
+				"3:9: Unexpected Star '*'",
+				"6:1: Unexpected RightCurlyBracket '}'",
+			},
+		},
+		{
+			name: "unclosed brackets end with the body",
+			src:  "init {\n  x = 1 +\n  y = (1, 2\n  print 'ok'\n}\n\npage {\n  a = [1, 2\n  print a\n}\n\nother {\n  z = )\n}\n",
+			errs: []string{
+				"3:5: Unexpected Equal '='",
+				"9:3: expect ']'. Got unexpected Print 'print'",
+				"13:7: Unexpected RightParen ')'",
+			},
+		},
+		{
+			name: "unexpected end of file",
+			src:  "init {\n  a = [",
+			errs: []string{
+				"2:8: expect ']'. Got unexpected EOF",
+				"2:8: Expect '}' to close body. Got unexpected EOF",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if errs := parseErrs(t, test.src); !reflect.DeepEqual(errs, test.errs) {
+				t.Errorf("got errors %q, want %q", errs, test.errs)
+			}
+		})
+	}
+}
+
+// parenthesize writes the expression with every unary and binary expression in parenthesis
+func parenthesize(expr Expr) string {
+	switch e := expr.(type) {
+	case BinaryExpr:
+		return fmt.Sprintf("(%s %s %s)", parenthesize(e.Left), e.Operator.Lexeme, parenthesize(e.Right))
+	case UnaryExpr:
+		return fmt.Sprintf("(%s %s)", e.Operator.Lexeme, parenthesize(e.Right))
+	case GroupingExpr:
+		return parenthesize(e.Expr)
+	case LiteralExpr:
+		return e.Value.Lexeme
+	case IdentExpr:
+		return e.Name.Lexeme
+	default:
+		return fmt.Sprintf("%T", expr)
+	}
+}
+
+func TestPrecedence(t *testing.T) {
+	tests := []struct {
+		src  string
+		want string
+	}{
+		{"1 + 2 * 3", "(1 + (2 * 3))"},
+		{"1 - 2 - 3", "((1 - 2) - 3)"},
+		{"-a * b", "((- a) * b)"},
+		{"a < b == c", "((a < b) == c)"},
+		{"not status == 200", "(not (status == 200))"},
+		{"not a < b", "(not (a < b))"},
+		{"not a and b", "((not a) and b)"},
+		{"a or not b and c", "(a or ((not b) and c))"},
+		{"not not a", "(not (not a))"},
+		{"not -a + 1 > 2", "(not (((- a) + 1) > 2))"},
+		{"(not a) == b", "((not a) == b)"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.src, func(t *testing.T) {
+			tokens, err := token.NewScanner([]byte(test.src)).ScanTokens()
+			if err != nil {
+				t.Fatalf("scanning the source: %s", err)
+			}
+			expr, err := New(tokens).ParseExpression()
+			if err != nil {
+				t.Fatalf("parsing the expression: %s", err)
+			}
+			if got := parenthesize(expr); got != test.want {
+				t.Errorf("got %s, want %s", got, test.want)
+			}
+		})
+	}
+}
+
+func TestConditions(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+	}{
+		{"parenthesized if condition", "if (found) {\n  print found\n}\n"},
+		{"parenthesized while condition", "while (x) {\n  x = false\n}\n"},
+		{"parenthesized for iterable", "for item in (items) {\n  print item\n}\n"},
+		{"grouped comparison", "if (a) == (b) {\n  print a\n}\n"},
+		{"closure argument", "if any(items, (x) {\n  return x\n}) {\n  print items\n}\n"},
+		{"closure within parenthesis", "if (f((x) {\n  return x\n})) {\n  print f\n}\n"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			tokens, err := token.NewScanner([]byte(test.src)).ScanTokens()
+			if err != nil {
+				t.Fatalf("scanning the source: %s", err)
+			}
+			p := New(tokens)
+			if _, err := p.ParseStatements(); err != nil {
+				t.Fatalf("parsing the statements: %s", err)
+			}
+			if p.HasErrs() {
+				t.Errorf("unexpected syntax errors %v", p.Err())
+			}
+		})
+	}
+}
+
+func TestSingleLineBodies(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+	}{
+		{"get", "if ok { get url }\n"},
+		{"get with headers", "if ok { @page get url, {'a': 'b'} }\n"},
+		{"post", "if ok { post url, body }\n"},
+		{"print", "if ok { print url } else { print 'none' }\n"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			tokens, err := token.NewScanner([]byte(test.src)).ScanTokens()
+			if err != nil {
+				t.Fatalf("scanning the source: %s", err)
+			}
+			p := New(tokens)
+			if _, err := p.ParseStatements(); err != nil {
+				t.Fatalf("parsing the statements: %s", err)
+			}
+			if p.HasErrs() {
+				t.Errorf("unexpected syntax errors %v", p.Err())
+			}
+		})
+	}
+}
@@ -0,0 +1,67 @@
+package parser
+
+import "github.com/kingzbauer/scraperlang/token"
+
+// FirstToken returns the left most token of the expression, which is used to locate the
+// expression within the source. It returns nil for unknown expression types
+func FirstToken(expr Expr) *token.Token {
+	switch e := expr.(type) {
+	case TaggedClosure:
+		return e.Name
+	case GetExpr:
+		return e.Method
+	case RequestExpr:
+		return e.Method
+	case PrintExpr:
+		return e.Keyword
+	case EmitExpr:
+		return e.Keyword
+	case AssignExpr:
+		return e.Name
+	case CallExpr:
+		return FirstToken(e.Name)
+	case ClosureExpr:
+		return e.Paren
+	case AccessExpr:
+		return FirstToken(e.Var)
+	case MapAccessExpr:
+		return FirstToken(e.Name)
+	case HTMLAttrAccessor:
+		return FirstToken(e.Var)
+	case ArrayExpr:
+		return e.Bracket
+	case MapExpr:
+		return e.Brace
+	case LiteralExpr:
+		return e.Value
+	case IdentExpr:
+		return e.Name
+	case ReturnExpr:
+		return e.Keyword
+	case BodyExpr:
+		return e.Brace
+	case BinaryExpr:
+		return FirstToken(e.Left)
+	case UnaryExpr:
+		return e.Operator
+	case GroupingExpr:
+		return e.Paren
+	case IfExpr:
+		return e.Keyword
+	case ForExpr:
+		return e.Keyword
+	case WhileExpr:
+		return e.Keyword
+	case TryExpr:
+		return e.Keyword
+	case BreakExpr:
+		return e.Keyword
+	case ContinueExpr:
+		return e.Keyword
+	case InterpolationExpr:
+		if len(e.Parts) > 0 {
+			return FirstToken(e.Parts[0])
+		}
+	}
+	return nil
+}
@@ -0,0 +1,70 @@
+package printer
+
+import (
+	"reflect"
+
+	"github.com/kingzbauer/scraperlang/parser"
+	"github.com/kingzbauer/scraperlang/token"
+)
+
+var tokenType = reflect.TypeOf(&token.Token{})
+
+// Equal checks whether both ASTs are made up of the same expressions, ignoring the positions of
+// their tokens
+func Equal(a, b []parser.Expr) bool {
+	return equal(reflect.ValueOf(a), reflect.ValueOf(b))
+}
+
+func equal(a, b reflect.Value) bool {
+	if a.Kind() != b.Kind() {
+		return false
+	}
+	if a.Type() == tokenType {
+		if a.IsNil() || b.IsNil() {
+			return a.IsNil() == b.IsNil()
+		}
+		x, y := a.Interface().(*token.Token), b.Interface().(*token.Token)
+		return x.Type == y.Type && x.Lexeme == y.Lexeme && reflect.DeepEqual(x.Literal, y.Literal)
+	}
+
+	switch a.Kind() {
+	case reflect.Interface:
+		if a.IsNil() || b.IsNil() {
+			return a.IsNil() == b.IsNil()
+		}
+		if a.Elem().Type() != b.Elem().Type() {
+			return false
+		}
+		return equal(a.Elem(), b.Elem())
+	case reflect.Slice:
+		if a.Len() != b.Len() {
+			return false
+		}
+		for index := 0; index < a.Len(); index++ {
+			if !equal(a.Index(index), b.Index(index)) {
+				return false
+			}
+		}
+		return true
+	case reflect.Map:
+		if a.Len() != b.Len() {
+			return false
+		}
+		for _, key := range a.MapKeys() {
+			value := b.MapIndex(key)
+			if !value.IsValid() || !equal(a.MapIndex(key), value) {
+				return false
+			}
+		}
+		return true
+	case reflect.Struct:
+		for index := 0; index < a.NumField(); index++ {
+			if !equal(a.Field(index), b.Field(index)) {
+				return false
+			}
+		}
+		return true
+	default:
+		return reflect.DeepEqual(a.Interface(), b.Interface())
+	}
+}
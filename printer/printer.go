@@ -0,0 +1,681 @@
+package printer
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/kingzbauer/scraperlang/parser"
+	"github.com/kingzbauer/scraperlang/token"
+)
+
+// Layout of the canonical source
+const (
+	indentation = "  "
+	maxWidth    = 80
+)
+
+// ErrRoundTrip is returned when the formatted source doesn't parse back into the original AST
+var ErrRoundTrip = errors.New("formatted source does not parse into the original syntax tree")
+
+// Printer implements the Visitor interface and writes the canonical source of the AST.
+//
+//	1. Statements are indented by two spaces and separated by at most one blank line
+//	2. Tagged closures are separated by a single blank line
+//	3. Maps and arrays are kept on one line if they fit within 80 columns and contain neither
+//	   closures nor comments, otherwise every entry is written on it's own line. Map keys are sorted
+//	4. Literals are written the way they appear in the source
+//
+// Comments are written before the statement or entry that follows them, or at the end of the line
+// they trail
+type Printer struct {
+	buf *strings.Builder
+	// comments are the comments that haven't been written yet, in source order
+	comments token.Tokens
+	indent   int
+	// column is the width of the current output line
+	column int
+	// lineStart is set when nothing has been written on the current output line
+	lineStart bool
+	// line is the source line of the last token written
+	line int
+	// flat is set when measuring the one line layout of an expression
+	flat bool
+}
+
+func newPrinter(comments token.Tokens) *Printer {
+	return &Printer{buf: &strings.Builder{}, comments: comments, lineStart: true}
+}
+
+// Fprint writes the canonical source of the AST to w. The tokens are the tokens of the source
+// scanned with token.WithComments, their comments are kept in the output
+func Fprint(w io.Writer, ast []parser.Expr, tokens token.Tokens) error {
+	var comments token.Tokens
+	for _, t := range tokens {
+		if t.Type == token.Comment {
+			comments = append(comments, t)
+		}
+	}
+	p := newPrinter(comments)
+	for index, expr := range ast {
+		if index > 0 {
+			p.blankLine()
+		}
+		p.leading(parser.FirstToken(expr), true)
+		expr.Accept(p, nil)
+		p.newline()
+	}
+	p.remaining(len(ast) == 0)
+	_, err := io.WriteString(w, p.buf.String())
+	return err
+}
+
+// Format scans, parses and formats the source. The formatted source is parsed again to make sure
+// it results in the same AST, ErrRoundTrip is returned otherwise
+func Format(src []byte) ([]byte, error) {
+	tokens, err := token.NewScanner(src, token.WithComments()).ScanTokens()
+	if err != nil {
+		return nil, err
+	}
+	ast, err := parse(tokens)
+	if err != nil {
+		return nil, err
+	}
+
+	buf := &strings.Builder{}
+	if err := Fprint(buf, ast, tokens); err != nil {
+		return nil, err
+	}
+
+	tokens, err = token.NewScanner([]byte(buf.String())).ScanTokens()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrRoundTrip, err)
+	}
+	formatted, err := parse(tokens)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrRoundTrip, err)
+	}
+	if !Equal(ast, formatted) {
+		return nil, ErrRoundTrip
+	}
+	return []byte(buf.String()), nil
+}
+
+// parse returns the AST of the tokens or the first syntax error
+func parse(tokens token.Tokens) ([]parser.Expr, error) {
+	p := parser.New(tokens)
+	ast, err := p.Parse()
+	if err != nil {
+		return nil, err
+	}
+	if p.HasErrs() {
+		return nil, p.Err()[0]
+	}
+	return ast, nil
+}
+
+func (p *Printer) write(s string) {
+	if s == "" {
+		return
+	}
+	if p.lineStart {
+		p.lineStart = false
+		ind := strings.Repeat(indentation, p.indent)
+		p.buf.WriteString(ind)
+		p.column = len(ind)
+	}
+	p.buf.WriteString(s)
+	if index := strings.LastIndexByte(s, '\n'); index >= 0 {
+		p.column = len(s) - index - 1
+	} else {
+		p.column += len(s)
+	}
+}
+
+// token writes the lexeme of the token as it appears in the source
+func (p *Printer) token(t *token.Token) {
+	p.write(t.Lexeme)
+	p.advance(t)
+}
+
+// advance records that the source has been written up to the token
+func (p *Printer) advance(t *token.Token) {
+	if t == nil {
+		return
+	}
+	if line := t.Line + strings.Count(t.Lexeme, "\n"); line > p.line {
+		p.line = line
+	}
+}
+
+// newline ends the current output line. Comments that are pending up to the source line of the last
+// token written trail the line, the first on the same line and the rest on their own lines
+func (p *Printer) newline() {
+	p.newlineBefore(nil)
+}
+
+// newlineBefore ends the current output line the same way as newline, except that only the comments
+// that precede the token trail the line. The rest are left to the token that follows them
+func (p *Printer) newlineBefore(t *token.Token) {
+	trailing := 0
+	for trailing < len(p.comments) && p.comments[trailing].Line <= p.line &&
+		(t == nil || before(p.comments[trailing], t)) {
+		trailing++
+	}
+	for index, comment := range p.comments[:trailing] {
+		if index == 0 && !p.lineStart {
+			p.write(" ")
+		} else if index > 0 {
+			p.endLine()
+		}
+		p.write(comment.Lexeme)
+		p.advance(comment)
+	}
+	p.comments = p.comments[trailing:]
+	p.endLine()
+}
+
+func (p *Printer) endLine() {
+	p.buf.WriteByte('\n')
+	p.lineStart = true
+	p.column = 0
+}
+
+func (p *Printer) blankLine() {
+	p.buf.WriteByte('\n')
+}
+
+// leading writes the comments that precede the token on their own lines, keeping a single blank
+// line wherever the source has one or more. No blank line is added at the start of a block
+func (p *Printer) leading(t *token.Token, blockStart bool) {
+	if t == nil {
+		return
+	}
+	for p.pendingBefore(t) {
+		blockStart = p.comment(blockStart)
+	}
+	if !blockStart && t.Line > p.line+1 {
+		p.blankLine()
+	}
+}
+
+// remaining writes the comments that follow the last tagged closure
+func (p *Printer) remaining(blockStart bool) {
+	for len(p.comments) > 0 {
+		blockStart = p.comment(blockStart)
+	}
+}
+
+// comment writes the next pending comment on it's own line
+func (p *Printer) comment(blockStart bool) bool {
+	comment := p.comments[0]
+	p.comments = p.comments[1:]
+	if !blockStart && comment.Line > p.line+1 {
+		p.blankLine()
+	}
+	p.write(comment.Lexeme)
+	p.endLine()
+	p.advance(comment)
+	return false
+}
+
+// pendingBefore checks whether there are comments that haven't been written before the token
+func (p *Printer) pendingBefore(t *token.Token) bool {
+	return t != nil && len(p.comments) > 0 && before(p.comments[0], t)
+}
+
+func before(a, b *token.Token) bool {
+	return a.Line < b.Line || (a.Line == b.Line && a.Column < b.Column)
+}
+
+// flatten returns the one line layout of the expression. It fails if the expression can't be
+// written on a single line e.g it contains a closure
+func (p *Printer) flatten(expr parser.Expr) (string, bool) {
+	flat := newPrinter(nil)
+	flat.flat = true
+	ok := true
+	func() {
+		defer func() {
+			if val := recover(); val != nil {
+				if val != errMultiline {
+					panic(val)
+				}
+				ok = false
+			}
+		}()
+		expr.Accept(flat, nil)
+	}()
+	return flat.buf.String(), ok
+}
+
+// errMultiline is used to abort measuring the one line layout of an expression
+var errMultiline = errors.New("expression spans multiple lines")
+
+// list writes the entries enclosed by the brackets, on a single line if they fit, otherwise one
+// entry per line
+func (p *Printer) list(open, close *token.Token, entries []parser.Expr) {
+	if len(entries) == 0 && !p.pendingBefore(close) {
+		p.token(open)
+		p.token(close)
+		return
+	}
+	if !p.pendingBefore(close) {
+		buf := &strings.Builder{}
+		ok := true
+		for index, e := range entries {
+			if index > 0 {
+				buf.WriteString(", ")
+			}
+			s, flat := p.flatten(e)
+			buf.WriteString(s)
+			ok = ok && flat
+		}
+		if ok && p.column+len(buf.String())+2 <= maxWidth {
+			p.token(open)
+			p.write(buf.String())
+			p.token(close)
+			return
+		}
+	}
+	if p.flat {
+		panic(errMultiline)
+	}
+
+	// A comment trails the entry, or the opening bracket, that precedes it in the source
+	p.token(open)
+	p.newlineBefore(following(open, entries))
+	p.indent++
+	for index, e := range entries {
+		p.leading(firstToken(e), index == 0)
+		e.Accept(p, nil)
+		if index < len(entries)-1 {
+			p.write(",")
+		}
+		p.newlineBefore(following(firstToken(e), entries))
+	}
+	p.leading(close, len(entries) == 0)
+	p.indent--
+	p.token(close)
+}
+
+// firstToken returns the left most token of a list entry
+func firstToken(expr parser.Expr) *token.Token {
+	if entry, ok := expr.(mapEntry); ok {
+		return parser.FirstToken(entry.value)
+	}
+	return parser.FirstToken(expr)
+}
+
+// following returns the first token of the entry that follows the token in the source, nil if
+// there is none
+func following(t *token.Token, entries []parser.Expr) *token.Token {
+	var next *token.Token
+	if t == nil {
+		return next
+	}
+	for _, e := range entries {
+		if first := firstToken(e); first != nil && before(t, first) && (next == nil || before(first, next)) {
+			next = first
+		}
+	}
+	return next
+}
+
+// exprs writes the expressions separated by a comma
+func (p *Printer) exprs(exprs []parser.Expr) {
+	for index, expr := range exprs {
+		if index > 0 {
+			p.write(", ")
+		}
+		expr.Accept(p, nil)
+	}
+}
+
+// VisitTaggedClosure writes the tagged closure with it's options
+func (p *Printer) VisitTaggedClosure(expr parser.TaggedClosure, e parser.Environment) interface{} {
+	p.token(expr.Name)
+	if len(expr.Options) > 0 {
+		p.write("(")
+		for index, option := range expr.Options {
+			if index > 0 {
+				p.write(", ")
+			}
+			p.token(option.Name)
+			p.write(": ")
+			option.Value.Accept(p, e)
+		}
+		p.write(")")
+	}
+	p.write(" ")
+	expr.Body.Accept(p, e)
+	return nil
+}
+
+// VisitBodyExpr writes every statement of the body on it's own line
+func (p *Printer) VisitBodyExpr(expr parser.BodyExpr, e parser.Environment) interface{} {
+	if p.flat {
+		panic(errMultiline)
+	}
+	p.token(expr.Brace)
+	if len(expr.Exprs) == 0 && !p.pendingBefore(expr.RightBrace) {
+		p.token(expr.RightBrace)
+		return nil
+	}
+	p.newline()
+	p.indent++
+	for index, stmt := range expr.Exprs {
+		p.leading(parser.FirstToken(stmt), index == 0)
+		stmt.Accept(p, e)
+		p.newline()
+	}
+	p.leading(expr.RightBrace, len(expr.Exprs) == 0)
+	p.indent--
+	p.token(expr.RightBrace)
+	return nil
+}
+
+// VisitGetExpr writes a get request
+func (p *Printer) VisitGetExpr(expr parser.GetExpr, e parser.Environment) interface{} {
+	p.request(expr.Tag, expr.Method, expr.URL, expr.Header)
+	return nil
+}
+
+// VisitRequestExpr writes a request with an optional body and headers
+func (p *Printer) VisitRequestExpr(expr parser.RequestExpr, e parser.Environment) interface{} {
+	p.request(expr.Tag, expr.Method, expr.URL, expr.Body, expr.Header)
+	return nil
+}
+
+func (p *Printer) request(tag, method *token.Token, args ...parser.Expr) {
+	if tag != nil {
+		p.token(tag)
+		p.write(" ")
+	}
+	p.token(method)
+	p.write(" ")
+	for index, arg := range args {
+		if arg == nil {
+			break
+		}
+		if index > 0 {
+			p.write(", ")
+		}
+		arg.Accept(p, nil)
+	}
+}
+
+// VisitPrintExpr writes a print statement
+func (p *Printer) VisitPrintExpr(expr parser.PrintExpr, e parser.Environment) interface{} {
+	p.token(expr.Keyword)
+	p.write(" ")
+	p.exprs(expr.Args)
+	return nil
+}
+
+// VisitEmitExpr writes an emit statement
+func (p *Printer) VisitEmitExpr(expr parser.EmitExpr, e parser.Environment) interface{} {
+	p.token(expr.Keyword)
+	p.write(" ")
+	expr.Record.Accept(p, e)
+	return nil
+}
+
+// VisitAssignExpr writes an assignment
+func (p *Printer) VisitAssignExpr(expr parser.AssignExpr, e parser.Environment) interface{} {
+	p.token(expr.Name)
+	p.write(" = ")
+	expr.Value.Accept(p, e)
+	return nil
+}
+
+// VisitCallExpr writes a call, keeping free form calls without their parenthesis
+func (p *Printer) VisitCallExpr(expr parser.CallExpr, e parser.Environment) interface{} {
+	expr.Name.Accept(p, e)
+	if expr.Paren == nil {
+		p.write(" ")
+		p.exprs(expr.Arguments)
+		return nil
+	}
+	p.token(expr.Paren)
+	p.exprs(expr.Arguments)
+	p.write(")")
+	return nil
+}
+
+// VisitClosureExpr writes the parameters and body of a closure
+func (p *Printer) VisitClosureExpr(expr parser.ClosureExpr, e parser.Environment) interface{} {
+	p.token(expr.Paren)
+	for index, param := range expr.Params {
+		if index > 0 {
+			p.write(", ")
+		}
+		p.token(param)
+	}
+	p.write(") ")
+	expr.Body.Accept(p, e)
+	return nil
+}
+
+// VisitAccessExpr writes an attribute access
+func (p *Printer) VisitAccessExpr(expr parser.AccessExpr, e parser.Environment) interface{} {
+	expr.Var.Accept(p, e)
+	p.write(".")
+	p.token(expr.Field)
+	return nil
+}
+
+// VisitHTMLAttrAccessor writes an html attribute access
+func (p *Printer) VisitHTMLAttrAccessor(expr parser.HTMLAttrAccessor, e parser.Environment) interface{} {
+	expr.Var.Accept(p, e)
+	p.write("~")
+	p.token(expr.Attr)
+	return nil
+}
+
+// VisitMapAccessExpr writes an index into a map or array
+func (p *Printer) VisitMapAccessExpr(expr parser.MapAccessExpr, e parser.Environment) interface{} {
+	expr.Name.Accept(p, e)
+	p.token(expr.Bracket)
+	expr.Key.Accept(p, e)
+	p.write("]")
+	return nil
+}
+
+// VisitArrayExpr writes an array literal
+func (p *Printer) VisitArrayExpr(expr parser.ArrayExpr, e parser.Environment) interface{} {
+	p.list(expr.Bracket, expr.RightBracket, expr.Entries)
+	return nil
+}
+
+// VisitMapExpr writes a map literal with it's keys sorted
+func (p *Printer) VisitMapExpr(expr parser.MapExpr, e parser.Environment) interface{} {
+	keys := make([]string, 0, len(expr.Entries))
+	for key := range expr.Entries {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	entries := make([]parser.Expr, len(keys))
+	for index, key := range keys {
+		entries[index] = mapEntry{key: key, value: expr.Entries[key]}
+	}
+	p.list(expr.Brace, expr.RightBrace, entries)
+	return nil
+}
+
+// mapEntry is a key value pair of a map literal
+type mapEntry struct {
+	key   string
+	value parser.Expr
+}
+
+// Accept writes the map entry
+func (m mapEntry) Accept(visitor parser.Visitor, env parser.Environment) interface{} {
+	p := visitor.(*Printer)
+	p.write(quote(m.key))
+	p.write(": ")
+	m.value.Accept(p, env)
+	return nil
+}
+
+// VisitLiteralExpr writes the literal as it appears in the source
+func (p *Printer) VisitLiteralExpr(expr parser.LiteralExpr, e parser.Environment) interface{} {
+	p.token(expr.Value)
+	return nil
+}
+
+// VisitIdentExpr writes the identifier
+func (p *Printer) VisitIdentExpr(expr parser.IdentExpr, e parser.Environment) interface{} {
+	p.token(expr.Name)
+	return nil
+}
+
+// VisitReturnExpr writes a return statement
+func (p *Printer) VisitReturnExpr(expr parser.ReturnExpr, e parser.Environment) interface{} {
+	p.token(expr.Keyword)
+	if expr.Value != nil {
+		p.write(" ")
+		expr.Value.Accept(p, e)
+	}
+	return nil
+}
+
+// VisitBinaryExpr writes the operands separated by the operator
+func (p *Printer) VisitBinaryExpr(expr parser.BinaryExpr, e parser.Environment) interface{} {
+	expr.Left.Accept(p, e)
+	p.write(" ")
+	p.token(expr.Operator)
+	p.write(" ")
+	expr.Right.Accept(p, e)
+	return nil
+}
+
+// VisitUnaryExpr writes the operator followed by the operand
+func (p *Printer) VisitUnaryExpr(expr parser.UnaryExpr, e parser.Environment) interface{} {
+	p.token(expr.Operator)
+	if expr.Operator.Type == token.Not {
+		p.write(" ")
+	}
+	expr.Right.Accept(p, e)
+	return nil
+}
+
+// VisitGroupingExpr writes the parenthesized expression
+func (p *Printer) VisitGroupingExpr(expr parser.GroupingExpr, e parser.Environment) interface{} {
+	p.token(expr.Paren)
+	expr.Expr.Accept(p, e)
+	p.write(")")
+	return nil
+}
+
+// VisitIfExpr writes an if statement and it's else branches
+func (p *Printer) VisitIfExpr(expr parser.IfExpr, e parser.Environment) interface{} {
+	p.token(expr.Keyword)
+	p.write(" ")
+	expr.Condition.Accept(p, e)
+	p.write(" ")
+	expr.Then.Accept(p, e)
+	if expr.Else != nil {
+		p.write(" else ")
+		expr.Else.Accept(p, e)
+	}
+	return nil
+}
+
+// VisitForExpr writes a for loop
+func (p *Printer) VisitForExpr(expr parser.ForExpr, e parser.Environment) interface{} {
+	p.token(expr.Keyword)
+	p.write(" ")
+	if expr.Key != nil {
+		p.token(expr.Key)
+		p.write(", ")
+	}
+	p.token(expr.Value)
+	p.write(" in ")
+	expr.Iterable.Accept(p, e)
+	p.write(" ")
+	expr.Body.Accept(p, e)
+	return nil
+}
+
+// VisitWhileExpr writes a while loop
+func (p *Printer) VisitWhileExpr(expr parser.WhileExpr, e parser.Environment) interface{} {
+	p.token(expr.Keyword)
+	p.write(" ")
+	expr.Condition.Accept(p, e)
+	p.write(" ")
+	expr.Body.Accept(p, e)
+	return nil
+}
+
+// VisitTryExpr writes a try statement along with it's catch clause
+func (p *Printer) VisitTryExpr(expr parser.TryExpr, e parser.Environment) interface{} {
+	p.token(expr.Keyword)
+	p.write(" ")
+	expr.Body.Accept(p, e)
+	p.write(" ")
+	p.token(expr.Catch)
+	p.write(" ")
+	p.token(expr.Name)
+	p.write(" ")
+	expr.Handler.Accept(p, e)
+	return nil
+}
+
+// VisitBreakExpr writes a break statement
+func (p *Printer) VisitBreakExpr(expr parser.BreakExpr, e parser.Environment) interface{} {
+	p.token(expr.Keyword)
+	return nil
+}
+
+// VisitContinueExpr writes a continue statement
+func (p *Printer) VisitContinueExpr(expr parser.ContinueExpr, e parser.Environment) interface{} {
+	p.token(expr.Keyword)
+	return nil
+}
+
+// VisitInterpolationExpr writes the string segments and the embedded expressions
+func (p *Printer) VisitInterpolationExpr(expr parser.InterpolationExpr, e parser.Environment) interface{} {
+	for _, part := range expr.Parts {
+		part.Accept(p, e)
+	}
+	return nil
+}
+
+// quote returns a double quoted string literal that the scanner decodes back into s
+func quote(s string) string {
+	buf := &strings.Builder{}
+	buf.WriteByte('"')
+	for index, r := range s {
+		switch r {
+		case '"':
+			buf.WriteString(`\"`)
+		case '\\':
+			buf.WriteString(`\\`)
+		case '\n':
+			buf.WriteString(`\n`)
+		case '\t':
+			buf.WriteString(`\t`)
+		case '\r':
+			buf.WriteString(`\r`)
+		case 0:
+			buf.WriteString(`\0`)
+		case '$':
+			// Avoid starting an interpolation
+			if strings.HasPrefix(s[index:], "${") {
+				buf.WriteString(`\$`)
+			} else {
+				buf.WriteRune(r)
+			}
+		default:
+			if r < ' ' || r == 0x7f {
+				fmt.Fprintf(buf, `\u%04x`, r)
+			} else {
+				buf.WriteRune(r)
+			}
+		}
+	}
+	buf.WriteByte('"')
+	return buf.String()
+}
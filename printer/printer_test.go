@@ -0,0 +1,142 @@
+package printer
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/kingzbauer/scraperlang/parser"
+	"github.com/kingzbauer/scraperlang/token"
+)
+
+func TestFprintRoundTrip(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		// comments are expected to be kept in the formatted source
+		comments []string
+	}{
+		{
+			name: "comments",
+			src: `// leading comment
+init {
+  # hash comment
+  url = "https://example.com" // trailing comment
+  /* block
+     comment */
+  @page get url
+}
+
+page {
+  print status
+}
+`,
+			comments: []string{"// leading comment", "# hash comment", "// trailing comment", "/* block\n     comment */"},
+		},
+		{
+			name: "block comment between statements",
+			src: `init {
+  a = 1 /* first
+  */ b = 2
+  print a, b
+}
+`,
+			comments: []string{"/* first\n  */"},
+		},
+		{
+			name: "comments trailing list entries",
+			src: `init {
+  a = [1, # one
+    2, 3, # three
+    4]
+  b = [ # head
+    1, 2
+  ]
+  c = {'x': 1, // x
+    'y': [
+      4 # four
+    ]}
+}
+`,
+			comments: []string{"1, # one", "3, # three", "[ # head", `"x": 1, // x`, "4 # four"},
+		},
+		{
+			name: "interpolation",
+			src: `init {
+  name = "world"
+  print "hello ${name}, ${1 + 2} and ${"nested ${name}"}"
+}
+`,
+		},
+		{
+			name: "multiline and raw strings",
+			src: `init {
+  text = """first line
+second "quoted" line"""
+  raw = ` + "`C:\\path\\${none}`" + `
+  print text, raw
+}
+`,
+		},
+		{
+			name: "nested maps and arrays",
+			src: `init {
+  config = {'b': [1, 2, {'c': [3, [4, 5]]}], 'a': {'d': {'e': nil}}}
+  long = ['https://example.com/first', 'https://example.com/second', 'https://example.com/third']
+  handlers = {'parse': (x) {
+    return x
+  }, 'empty': {}, 'list': []}
+  @page get long
+}
+
+page (max_depth: 2) {
+  emit {'url': response.url, 'links': jq('a')~href}
+}
+`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			tokens, err := token.NewScanner([]byte(test.src), token.WithComments()).ScanTokens()
+			if err != nil {
+				t.Fatalf("scanning the source: %s", err)
+			}
+			ast, err := parser.New(tokens).Parse()
+			if err != nil {
+				t.Fatalf("parsing the source: %s", err)
+			}
+
+			buf := &strings.Builder{}
+			if err := Fprint(buf, ast, tokens); err != nil {
+				t.Fatalf("printing the source: %s", err)
+			}
+			formatted := buf.String()
+
+			tokens, err = token.NewScanner([]byte(formatted)).ScanTokens()
+			if err != nil {
+				t.Fatalf("scanning the formatted source: %s\n%s", err, formatted)
+			}
+			reparsed, err := parser.New(tokens).Parse()
+			if err != nil {
+				t.Fatalf("parsing the formatted source: %s\n%s", err, formatted)
+			}
+			if !Equal(ast, reparsed) {
+				t.Fatalf("the formatted source doesn't parse into the original syntax tree\n%s", formatted)
+			}
+			for _, comment := range test.comments {
+				if !strings.Contains(formatted, comment) {
+					t.Errorf("the comment %q is missing from the formatted source\n%s", comment, formatted)
+				}
+			}
+
+			// Formatting the canonical source leaves it unchanged
+			again, err := Format([]byte(formatted))
+			if err != nil {
+				t.Fatalf("formatting the formatted source: %s", err)
+			}
+			if string(again) != formatted {
+				t.Errorf("formatting isn't idempotent, got\n%s\nwant\n%s", again, formatted)
+			}
+		})
+	}
+}
@@ -0,0 +1,174 @@
+package repl
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/kingzbauer/scraperlang/diag"
+	"github.com/kingzbauer/scraperlang/interpreter"
+	"github.com/kingzbauer/scraperlang/parser"
+	"github.com/kingzbauer/scraperlang/token"
+)
+
+const help = `Enter statements or expressions, the value of an expression is printed.
+Input continues on the next line while a bracket is left open.
+
+Commands:
+  :fetch url  request the url and load the response into response, status, headers, content and jq
+  :help       print this help
+  :quit       exit the REPL`
+
+// REPL reads statements and expressions line by line and evaluates them in one persistent
+// environment, which allows trying out selectors against a live response loaded with :fetch
+type REPL struct {
+	in          *bufio.Scanner
+	out         io.Writer
+	interpreter *interpreter.Interpreter
+	env         parser.Environment
+	// reported is the number of interpreter errors that have already been printed
+	reported int
+}
+
+// New creates a REPL reading input from in and writing results and errors to out
+func New(in io.Reader, out io.Writer) (*REPL, error) {
+	i, err := interpreter.New(nil, interpreter.WithOutput(out))
+	if err != nil {
+		return nil, err
+	}
+	return &REPL{
+		in:          bufio.NewScanner(in),
+		out:         out,
+		interpreter: i,
+		env:         interpreter.NewEnvironment(nil, i.Globals()),
+	}, nil
+}
+
+// Run evaluates the input until it's exhausted or the :quit command is entered
+func (r *REPL) Run() error {
+	defer r.interpreter.Close()
+	fmt.Fprintln(r.out, "scraperlang REPL, enter :help for the list of commands")
+	for {
+		input, ok := r.read()
+		if !ok {
+			fmt.Fprintln(r.out)
+			return r.in.Err()
+		}
+		trimmed := strings.TrimSpace(input)
+		switch {
+		case trimmed == "":
+		case strings.HasPrefix(trimmed, ":"):
+			if !r.command(trimmed) {
+				return nil
+			}
+		default:
+			r.eval(input)
+		}
+	}
+}
+
+// read returns the next complete input, reading more lines while brackets are left open
+func (r *REPL) read() (string, bool) {
+	prompt := "> "
+	buf := &strings.Builder{}
+	for {
+		fmt.Fprint(r.out, prompt)
+		if !r.in.Scan() {
+			return "", false
+		}
+		buf.WriteString(r.in.Text())
+		buf.WriteByte('\n')
+		if !incomplete(buf.String()) {
+			return buf.String(), true
+		}
+		prompt = ".. "
+	}
+}
+
+// incomplete checks whether the input has brackets that are left open or an unterminated
+// multiline string
+func incomplete(input string) bool {
+	tokens, err := token.NewScanner([]byte(input)).ScanTokens()
+	if err != nil {
+		return strings.Count(input, `"""`)%2 == 1 || strings.Count(input, `'''`)%2 == 1 ||
+			strings.Count(input, "`")%2 == 1
+	}
+	depth := 0
+	for _, t := range tokens {
+		switch t.Type {
+		case token.LeftParen, token.LeftBracket, token.LeftCurlyBracket:
+			depth++
+		case token.RightParen, token.RightBracket, token.RightCurlyBracket:
+			depth--
+		}
+	}
+	return depth > 0
+}
+
+// command executes a REPL command, it returns false when the REPL should exit
+func (r *REPL) command(input string) bool {
+	fields := strings.Fields(input)
+	switch fields[0] {
+	case ":quit", ":q":
+		return false
+	case ":help":
+		fmt.Fprintln(r.out, help)
+	case ":fetch":
+		if len(fields) != 2 {
+			fmt.Fprintln(r.out, "usage: :fetch url")
+			break
+		}
+		if err := r.interpreter.Fetch(fields[1], r.env); err != nil {
+			fmt.Fprintln(r.out, err)
+			break
+		}
+		fmt.Fprintln(r.out, r.env.Get(token.Token{Lexeme: "response"}))
+	default:
+		fmt.Fprintf(r.out, "unknown command %s, enter :help for the list of commands\n", fields[0])
+	}
+	return true
+}
+
+// eval evaluates the input as an expression and prints it's value. Input that isn't a single
+// expression is evaluated as statements
+func (r *REPL) eval(input string) {
+	printer := diag.NewPrinter("<repl>", []byte(input))
+	tokens, err := token.NewScanner([]byte(input)).ScanTokens()
+	if err != nil {
+		printer.Print(r.out, err)
+		return
+	}
+
+	var stmts []parser.Expr
+	p := parser.New(tokens)
+	expr, err := p.ParseExpression()
+	if err == nil && !p.HasErrs() {
+		stmts = []parser.Expr{expr}
+	} else {
+		p = parser.New(tokens)
+		stmts, err = p.ParseStatements()
+		if err != nil {
+			printer.Print(r.out, err)
+			return
+		}
+		if p.HasErrs() {
+			for _, err := range p.Err() {
+				printer.Print(r.out, err)
+			}
+			return
+		}
+	}
+
+	val, err := r.interpreter.Eval(stmts, r.env)
+	errs := r.interpreter.Err()
+	for _, err := range errs[r.reported:] {
+		printer.Print(r.out, err)
+	}
+	r.reported = len(errs)
+	if err != nil {
+		printer.Print(r.out, err)
+	} else if val != nil && expr != nil {
+		fmt.Fprintln(r.out, val)
+	}
+}
@@ -0,0 +1,497 @@
+package resolver
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/kingzbauer/scraperlang/diag"
+	"github.com/kingzbauer/scraperlang/parser"
+	"github.com/kingzbauer/scraperlang/token"
+)
+
+// Error represents a semantic error found while resolving the AST
+type Error struct {
+	token    *token.Token
+	msg      string
+	severity diag.Severity
+}
+
+func (err Error) Error() string {
+	return err.Diagnostic().Error()
+}
+
+// Diagnostic implements the diag.Diagnoser interface
+func (err Error) Diagnostic() diag.Diagnostic {
+	return err.token.Diagnostic(err.severity, err.msg)
+}
+
+// Resolver performs a semantic analysis of the AST before it's handed over to the interpreter.
+// It checks for:
+//
+//	1. Missing but referenced tagged closures
+//	2. Redeclared tagged closures and a missing 'init' tagged closure
+//	3. Misused return, break and continue statements
+//	4. Invalid tagged closure options
+//	5. Requests to the 'error' tagged closure, which only handles the failed requests
+//
+// It also warns about cycles between tagged closures that have no crawl depth guard. A cycle is
+// guarded by a 'max_depth' option or by an if statement testing the `depth` variable
+type Resolver struct {
+	ast            []parser.Expr
+	taggedClosures map[string]*token.Token
+	// dispatches is the graph of the tagged closures each tagged closure makes requests to
+	dispatches map[string][]dispatch
+	// guarded holds the tagged closures with a 'max_depth' option
+	guarded map[string]bool
+	// current is the name of the tagged closure being resolved
+	current string
+	// depthGuards keeps track of how many if statements that test the crawl depth enclose
+	// the expression being resolved
+	depthGuards int
+	// readsDepth is set when the `depth` variable is referenced
+	readsDepth bool
+	// closures keeps track of how deep within untagged closures we are
+	closures int
+	// loops keeps track of how deep within loops of the current closure we are
+	loops    int
+	errs     []error
+	warnings []error
+}
+
+// dispatch is an edge in the tagged closure graph
+type dispatch struct {
+	tag   string
+	token *token.Token
+	// guarded is set when the dispatch only happens if a test of the crawl depth passes
+	guarded bool
+}
+
+// New creates and returns a new resolver for the provided AST
+func New(ast []parser.Expr) *Resolver {
+	return &Resolver{
+		ast:            ast,
+		taggedClosures: make(map[string]*token.Token),
+		dispatches:     make(map[string][]dispatch),
+		guarded:        make(map[string]bool),
+	}
+}
+
+// Err returns the errors found while resolving
+func (r *Resolver) Err() []error {
+	return r.errs
+}
+
+// HasErrs checks to see whether we have any resolver errors
+func (r *Resolver) HasErrs() bool {
+	return len(r.errs) > 0
+}
+
+// Warnings returns the problems found while resolving that don't prevent the script from running
+func (r *Resolver) Warnings() []error {
+	return r.warnings
+}
+
+func (r *Resolver) addErr(t *token.Token, msg string) {
+	r.errs = append(r.errs, Error{token: t, msg: msg})
+}
+
+func (r *Resolver) addWarning(t *token.Token, msg string) {
+	r.warnings = append(r.warnings, Error{token: t, msg: msg, severity: diag.Warning})
+}
+
+// Resolve walks the AST and records every problem found. Use Err to retrieve them
+func (r *Resolver) Resolve() {
+	// We first collect all the tagged closures so that references to closures that are declared
+	// later in the document can be resolved
+	for _, expr := range r.ast {
+		closure, ok := expr.(parser.TaggedClosure)
+		if !ok {
+			r.addErr(nil, "Only tagged closures are allowed as global variables")
+			continue
+		}
+		if previous, ok := r.taggedClosures[closure.Name.Lexeme]; ok {
+			r.addErr(closure.Name, fmt.Sprintf("tagged closure %q redeclared, previously declared at [%d:%d]",
+				closure.Name.Lexeme, previous.Line+1, previous.Column+1))
+			continue
+		}
+		r.taggedClosures[closure.Name.Lexeme] = closure.Name
+	}
+	if _, ok := r.taggedClosures["init"]; !ok {
+		r.addErr(nil, "Missing 'init' tagged closure")
+	}
+
+	for _, expr := range r.ast {
+		if closure, ok := expr.(parser.TaggedClosure); ok {
+			closure.Accept(r, nil)
+		}
+	}
+
+	r.resolveCycles()
+}
+
+// resolveOptions validates the options of a tagged closure
+func (r *Resolver) resolveOptions(expr parser.TaggedClosure) {
+	for _, option := range expr.Options {
+		switch option.Name.Lexeme {
+		case "max_depth":
+			literal, ok := option.Value.(parser.LiteralExpr)
+			var depth float64
+			if ok {
+				depth, ok = literal.Value.Literal.(float64)
+			}
+			if !ok || depth < 0 {
+				r.addErr(option.Name, "'max_depth' expects a non-negative number")
+				continue
+			}
+			r.guarded[expr.Name.Lexeme] = true
+		default:
+			r.addErr(option.Name, fmt.Sprintf("Unknown tagged closure option %q", option.Name.Lexeme))
+		}
+	}
+}
+
+// resolveCycles finds the strongly connected components of the tagged closure graph and warns about
+// every cycle that none of it's tagged closures guards with a 'max_depth' option. A 'max_depth' option
+// on 'init' guards every tagged closure
+func (r *Resolver) resolveCycles() {
+	if r.guarded["init"] {
+		return
+	}
+
+	var (
+		index    int
+		stack    []string
+		onStack  = make(map[string]bool)
+		indices  = make(map[string]int)
+		lowlinks = make(map[string]int)
+		connect  func(tag string)
+	)
+	connect = func(tag string) {
+		indices[tag] = index
+		lowlinks[tag] = index
+		index++
+		stack = append(stack, tag)
+		onStack[tag] = true
+
+		for _, d := range r.dispatches[tag] {
+			// A dispatch guarded by a depth test breaks any cycle it's part of
+			if d.guarded {
+				continue
+			}
+			if _, visited := indices[d.tag]; !visited {
+				connect(d.tag)
+				if lowlinks[d.tag] < lowlinks[tag] {
+					lowlinks[tag] = lowlinks[d.tag]
+				}
+			} else if onStack[d.tag] && indices[d.tag] < lowlinks[tag] {
+				lowlinks[tag] = indices[d.tag]
+			}
+		}
+
+		if lowlinks[tag] != indices[tag] {
+			return
+		}
+		// tag is the root of a strongly connected component
+		var component []string
+		for {
+			last := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			onStack[last] = false
+			component = append(component, last)
+			if last == tag {
+				break
+			}
+		}
+		r.checkCycle(component)
+	}
+
+	for _, expr := range r.ast {
+		if closure, ok := expr.(parser.TaggedClosure); ok {
+			if _, visited := indices[closure.Name.Lexeme]; !visited {
+				connect(closure.Name.Lexeme)
+			}
+		}
+	}
+}
+
+// checkCycle warns if the strongly connected component forms a cycle without a depth guard
+func (r *Resolver) checkCycle(component []string) {
+	members := make(map[string]bool, len(component))
+	for _, tag := range component {
+		if r.guarded[tag] {
+			return
+		}
+		members[tag] = true
+	}
+
+	// Report the cycle at the first dispatch that stays within the component, in the order the
+	// tagged closures are declared
+	for _, expr := range r.ast {
+		closure, ok := expr.(parser.TaggedClosure)
+		if !ok || !members[closure.Name.Lexeme] {
+			continue
+		}
+		for _, d := range r.dispatches[closure.Name.Lexeme] {
+			if !d.guarded && members[d.tag] {
+				names := make([]string, len(component))
+				for index, tag := range component {
+					names[len(component)-1-index] = fmt.Sprintf("%q", tag)
+				}
+				r.addWarning(d.token, fmt.Sprintf(
+					"tagged closures %s form a cycle without a depth guard, consider adding a 'max_depth' option",
+					strings.Join(names, ", ")))
+				return
+			}
+		}
+	}
+}
+
+func (r *Resolver) resolve(exprs ...parser.Expr) {
+	for _, expr := range exprs {
+		if expr != nil {
+			expr.Accept(r, nil)
+		}
+	}
+}
+
+// resolveTag checks that the tagged closure a request dispatches to exists and records the
+// dispatch in the tagged closure graph
+func (r *Resolver) resolveTag(tag, method *token.Token) {
+	if tag == nil {
+		if _, ok := r.taggedClosures["default"]; !ok {
+			r.addErr(method, fmt.Sprintf("'%s' without a tag requires a 'default' tagged closure", method.Lexeme))
+			return
+		}
+		r.addDispatch("default", method)
+		return
+	}
+	name := tag.Literal.(string)
+	if name == "error" {
+		r.addErr(tag, "The 'error' tagged closure handles the failed requests and can't be requested")
+		return
+	}
+	if _, ok := r.taggedClosures[name]; !ok {
+		r.addErr(tag, fmt.Sprintf("Unable to find the tagged closure %q", tag.Literal))
+		return
+	}
+	r.addDispatch(name, tag)
+}
+
+// addDispatch records the dispatch to the tagged closure in the tagged closure graph. A request
+// that fails is handed to the 'error' tagged closure instead, so it's a dispatch to it as well
+func (r *Resolver) addDispatch(tag string, t *token.Token) {
+	tags := []string{tag}
+	if _, ok := r.taggedClosures["error"]; ok {
+		tags = append(tags, "error")
+	}
+	for _, tag := range tags {
+		r.dispatches[r.current] = append(r.dispatches[r.current], dispatch{
+			tag:     tag,
+			token:   t,
+			guarded: r.depthGuards > 0,
+		})
+	}
+}
+
+// VisitTaggedClosure resolves the options and the body of the tagged closure
+func (r *Resolver) VisitTaggedClosure(expr parser.TaggedClosure, _ parser.Environment) interface{} {
+	r.current = expr.Name.Lexeme
+	r.resolveOptions(expr)
+	r.resolve(expr.Body)
+	return nil
+}
+
+// VisitGetExpr checks the referenced tagged closure and resolves the arguments
+func (r *Resolver) VisitGetExpr(expr parser.GetExpr, _ parser.Environment) interface{} {
+	r.resolveTag(expr.Tag, expr.Method)
+	r.resolve(expr.URL, expr.Header)
+	return nil
+}
+
+// VisitRequestExpr checks the referenced tagged closure and resolves the arguments
+func (r *Resolver) VisitRequestExpr(expr parser.RequestExpr, _ parser.Environment) interface{} {
+	r.resolveTag(expr.Tag, expr.Method)
+	r.resolve(expr.URL, expr.Body, expr.Header)
+	return nil
+}
+
+// VisitPrintExpr resolves the print arguments
+func (r *Resolver) VisitPrintExpr(expr parser.PrintExpr, _ parser.Environment) interface{} {
+	r.resolve(expr.Args...)
+	return nil
+}
+
+// VisitEmitExpr resolves the emitted record
+func (r *Resolver) VisitEmitExpr(expr parser.EmitExpr, _ parser.Environment) interface{} {
+	r.resolve(expr.Record)
+	return nil
+}
+
+// VisitAssignExpr resolves the assigned value
+func (r *Resolver) VisitAssignExpr(expr parser.AssignExpr, _ parser.Environment) interface{} {
+	r.resolve(expr.Value)
+	return nil
+}
+
+// VisitCallExpr resolves the callee and the arguments
+func (r *Resolver) VisitCallExpr(expr parser.CallExpr, _ parser.Environment) interface{} {
+	r.resolve(expr.Name)
+	r.resolve(expr.Arguments...)
+	return nil
+}
+
+// VisitClosureExpr resolves the closure body
+func (r *Resolver) VisitClosureExpr(expr parser.ClosureExpr, _ parser.Environment) interface{} {
+	// A closure can not break out of the loop it's defined in
+	loops := r.loops
+	r.loops = 0
+	r.closures++
+	r.resolve(expr.Body)
+	r.closures--
+	r.loops = loops
+	return nil
+}
+
+// VisitAccessExpr resolves the accessed value
+func (r *Resolver) VisitAccessExpr(expr parser.AccessExpr, _ parser.Environment) interface{} {
+	r.resolve(expr.Var)
+	return nil
+}
+
+// VisitHTMLAttrAccessor resolves the accessed node
+func (r *Resolver) VisitHTMLAttrAccessor(expr parser.HTMLAttrAccessor, _ parser.Environment) interface{} {
+	r.resolve(expr.Var)
+	return nil
+}
+
+// VisitArrayExpr resolves the array entries
+func (r *Resolver) VisitArrayExpr(expr parser.ArrayExpr, _ parser.Environment) interface{} {
+	r.resolve(expr.Entries...)
+	return nil
+}
+
+// VisitMapExpr resolves the map values
+func (r *Resolver) VisitMapExpr(expr parser.MapExpr, _ parser.Environment) interface{} {
+	for _, value := range expr.Entries {
+		r.resolve(value)
+	}
+	return nil
+}
+
+// VisitLiteralExpr has nothing to resolve
+func (r *Resolver) VisitLiteralExpr(_ parser.LiteralExpr, _ parser.Environment) interface{} {
+	return nil
+}
+
+// VisitIdentExpr records references to the crawl depth
+func (r *Resolver) VisitIdentExpr(expr parser.IdentExpr, _ parser.Environment) interface{} {
+	if expr.Name.Lexeme == "depth" {
+		r.readsDepth = true
+	}
+	return nil
+}
+
+// VisitMapAccessExpr resolves the indexed value and the key
+func (r *Resolver) VisitMapAccessExpr(expr parser.MapAccessExpr, _ parser.Environment) interface{} {
+	r.resolve(expr.Name, expr.Key)
+	return nil
+}
+
+// VisitReturnExpr checks that only untagged closures return a value
+func (r *Resolver) VisitReturnExpr(expr parser.ReturnExpr, _ parser.Environment) interface{} {
+	if expr.Value != nil && r.closures == 0 {
+		r.addErr(expr.Keyword, "a tagged closure can not return a value")
+	}
+	r.resolve(expr.Value)
+	return nil
+}
+
+// VisitBodyExpr resolves every statement in the body
+func (r *Resolver) VisitBodyExpr(expr parser.BodyExpr, _ parser.Environment) interface{} {
+	r.resolve(expr.Exprs...)
+	return nil
+}
+
+// VisitBinaryExpr resolves both operands
+func (r *Resolver) VisitBinaryExpr(expr parser.BinaryExpr, _ parser.Environment) interface{} {
+	r.resolve(expr.Left, expr.Right)
+	return nil
+}
+
+// VisitUnaryExpr resolves the operand
+func (r *Resolver) VisitUnaryExpr(expr parser.UnaryExpr, _ parser.Environment) interface{} {
+	r.resolve(expr.Right)
+	return nil
+}
+
+// VisitGroupingExpr resolves the enclosed expression
+func (r *Resolver) VisitGroupingExpr(expr parser.GroupingExpr, _ parser.Environment) interface{} {
+	r.resolve(expr.Expr)
+	return nil
+}
+
+// VisitIfExpr resolves the condition and both branches. Dispatches within the then branch of a
+// condition that tests the crawl depth are considered guarded
+func (r *Resolver) VisitIfExpr(expr parser.IfExpr, _ parser.Environment) interface{} {
+	readsDepth := r.readsDepth
+	r.readsDepth = false
+	r.resolve(expr.Condition)
+	guarded := r.readsDepth
+	r.readsDepth = readsDepth
+
+	if guarded {
+		r.depthGuards++
+	}
+	r.resolve(expr.Then)
+	if guarded {
+		r.depthGuards--
+	}
+	r.resolve(expr.Else)
+	return nil
+}
+
+// VisitForExpr resolves the iterable and the loop body
+func (r *Resolver) VisitForExpr(expr parser.ForExpr, _ parser.Environment) interface{} {
+	r.resolve(expr.Iterable)
+	r.loops++
+	r.resolve(expr.Body)
+	r.loops--
+	return nil
+}
+
+// VisitWhileExpr resolves the condition and the loop body
+func (r *Resolver) VisitWhileExpr(expr parser.WhileExpr, _ parser.Environment) interface{} {
+	r.resolve(expr.Condition)
+	r.loops++
+	r.resolve(expr.Body)
+	r.loops--
+	return nil
+}
+
+// VisitTryExpr resolves the body and the catch body
+func (r *Resolver) VisitTryExpr(expr parser.TryExpr, _ parser.Environment) interface{} {
+	r.resolve(expr.Body, expr.Handler)
+	return nil
+}
+
+// VisitBreakExpr checks that break is used within a loop
+func (r *Resolver) VisitBreakExpr(expr parser.BreakExpr, _ parser.Environment) interface{} {
+	if r.loops == 0 {
+		r.addErr(expr.Keyword, "'break' used outside of a loop")
+	}
+	return nil
+}
+
+// VisitContinueExpr checks that continue is used within a loop
+func (r *Resolver) VisitContinueExpr(expr parser.ContinueExpr, _ parser.Environment) interface{} {
+	if r.loops == 0 {
+		r.addErr(expr.Keyword, "'continue' used outside of a loop")
+	}
+	return nil
+}
+
+// VisitInterpolationExpr resolves the embedded expressions
+func (r *Resolver) VisitInterpolationExpr(expr parser.InterpolationExpr, _ parser.Environment) interface{} {
+	r.resolve(expr.Parts...)
+	return nil
+}
@@ -0,0 +1,157 @@
+package resolver
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/kingzbauer/scraperlang/diag"
+	"github.com/kingzbauer/scraperlang/parser"
+	"github.com/kingzbauer/scraperlang/token"
+)
+
+// resolve resolves the source and returns it's errors and warnings as 1-based
+// "line:column: message", or only the message if it has no position
+func resolve(t *testing.T, src string) (errs, warnings []string) {
+	t.Helper()
+	tokens, err := token.NewScanner([]byte(src)).ScanTokens()
+	if err != nil {
+		t.Fatalf("scanning the source: %s", err)
+	}
+	p := parser.New(tokens)
+	ast, err := p.Parse()
+	if err == nil && p.HasErrs() {
+		err = p.Err()[0]
+	}
+	if err != nil {
+		t.Fatalf("parsing the source: %s", err)
+	}
+	r := New(ast)
+	r.Resolve()
+	format := func(errs []error) []string {
+		var formatted []string
+		for _, err := range errs {
+			d := err.(diag.Diagnoser).Diagnostic()
+			if d.Start == diag.NoPosition {
+				formatted = append(formatted, d.Msg)
+			} else {
+				formatted = append(formatted, fmt.Sprintf("%d:%d: %s", d.Start.Line+1, d.Start.Column+1, d.Msg))
+			}
+		}
+		return formatted
+	}
+	return format(r.Err()), format(r.Warnings())
+}
+
+func TestResolveTags(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		errs []string
+	}{
+		{
+			name: "valid",
+			src:  "init {\n  @page get 'http://a'\n  get 'http://b'\n}\n\npage {\n  print status\n}\n\ndefault {\n  print status\n}\n",
+		},
+		{
+			name: "missing tagged closure",
+			src:  "init {\n  @page get 'http://a'\n}\n",
+			errs: []string{`2:3: Unable to find the tagged closure "page"`},
+		},
+		{
+			name: "missing default tagged closure",
+			src:  "init {\n  post 'http://a', 'body'\n}\n",
+			errs: []string{"2:3: 'post' without a tag requires a 'default' tagged closure"},
+		},
+		{
+			name: "requested error tagged closure",
+			src:  "init {\n  @error get 'http://a'\n}\n\nerror {\n  print cause\n}\n",
+			errs: []string{"2:3: The 'error' tagged closure handles the failed requests and can't be requested"},
+		},
+		{
+			name: "redeclared tagged closure",
+			src:  "init {\n  print 1\n}\n\n page {\n  print 1\n}\n\npage {\n  print 2\n}\n",
+			errs: []string{`9:1: tagged closure "page" redeclared, previously declared at [5:2]`},
+		},
+		{
+			name: "missing init",
+			src:  "page {\n  print 1\n}\n",
+			errs: []string{"Missing 'init' tagged closure"},
+		},
+		{
+			name: "invalid options",
+			src:  "init (max_depth: -1, retries: 2) {\n  print 1\n}\n",
+			errs: []string{"1:7: 'max_depth' expects a non-negative number", `1:22: Unknown tagged closure option "retries"`},
+		},
+		{
+			name: "misused statements",
+			src:  "init {\n  return 1\n  break\n  f = () {\n    continue\n  }\n}\n",
+			errs: []string{"2:3: a tagged closure can not return a value", "3:3: 'break' used outside of a loop",
+				"5:5: 'continue' used outside of a loop"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			errs, _ := resolve(t, test.src)
+			if !reflect.DeepEqual(errs, test.errs) {
+				t.Errorf("got errors %q, want %q", errs, test.errs)
+			}
+		})
+	}
+}
+
+func TestResolveCycles(t *testing.T) {
+	tests := []struct {
+		name     string
+		src      string
+		warnings []string
+	}{
+		{
+			name: "no cycle",
+			src:  "init {\n  @page get 'http://a'\n}\n\npage {\n  print status\n}\n",
+		},
+		{
+			name: "self cycle",
+			src:  "init {\n  @page get 'http://a'\n}\n\npage {\n  @page get 'http://b'\n}\n",
+			warnings: []string{`6:3: tagged closures "page" form a cycle without a depth guard, ` +
+				"consider adding a 'max_depth' option"},
+		},
+		{
+			name: "cycle through several tagged closures",
+			src:  "init {\n  @list get 'http://a'\n}\n\nlist {\n  @item get 'http://b'\n}\n\nitem {\n  @list get 'http://c'\n}\n",
+			warnings: []string{`6:3: tagged closures "list", "item" form a cycle without a depth guard, ` +
+				"consider adding a 'max_depth' option"},
+		},
+		{
+			name: "cycle guarded by max_depth",
+			src:  "init {\n  @list get 'http://a'\n}\n\nlist {\n  @item get 'http://b'\n}\n\nitem (max_depth: 3) {\n  @list get 'http://c'\n}\n",
+		},
+		{
+			name: "max_depth on init guards every cycle",
+			src:  "init (max_depth: 3) {\n  @page get 'http://a'\n}\n\npage {\n  @page get 'http://b'\n}\n",
+		},
+		{
+			name: "cycle guarded by a depth test",
+			src:  "init {\n  @page get 'http://a'\n}\n\npage {\n  if depth < 3 {\n    @page get 'http://b'\n  }\n}\n",
+		},
+		{
+			name: "retries of the error tagged closure",
+			src:  "init {\n  @page get 'http://a'\n}\n\npage {\n  print status\n}\n\nerror {\n  @page get url\n}\n",
+			warnings: []string{`10:3: tagged closures "error" form a cycle without a depth guard, ` +
+				"consider adding a 'max_depth' option"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			errs, warnings := resolve(t, test.src)
+			if len(errs) > 0 {
+				t.Fatalf("unexpected errors %q", errs)
+			}
+			if !reflect.DeepEqual(warnings, test.warnings) {
+				t.Errorf("got warnings %q, want %q", warnings, test.warnings)
+			}
+		})
+	}
+}
@@ -0,0 +1,22 @@
+package token
+
+import "encoding/json"
+
+// MarshalJSON encodes the token with it's type name and 1 based position e.g
+//
+//	{"type": "String", "lexeme": "'a'", "literal": "a", "line": 1, "column": 5}
+func (t Token) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Type    string      `json:"type"`
+		Lexeme  string      `json:"lexeme"`
+		Literal interface{} `json:"literal"`
+		Line    int         `json:"line"`
+		Column  int         `json:"column"`
+	}{
+		Type:    t.Type.String(),
+		Lexeme:  t.Lexeme,
+		Literal: t.Literal,
+		Line:    t.Line + 1,
+		Column:  t.Column + 1,
+	})
+}
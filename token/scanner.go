@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"strconv"
 	"strings"
+
+	"github.com/kingzbauer/scraperlang/diag"
 )
 
 // Token is a lexer/scanner output
@@ -38,6 +40,15 @@ func (t Token) String() string {
 	return fmt.Sprintf("%s", t.Lexeme)
 }
 
+// Diagnostic creates a diagnostic spanning the lexeme of the token. A nil token results in a
+// diagnostic without a position
+func (t *Token) Diagnostic(severity diag.Severity, msg string) diag.Diagnostic {
+	if t == nil {
+		return diag.Diagnostic{Start: diag.NoPosition, End: diag.NoPosition, Severity: severity, Msg: msg}
+	}
+	return diag.New(severity, t.Line, t.Column, t.Lexeme, msg)
+}
+
 // Error returned when the scanner encounters an unexpected character
 type Error struct {
 	Line, Column int
@@ -45,33 +56,71 @@ type Error struct {
 }
 
 func (err Error) Error() string {
-	return fmt.Sprintf("[%d:%d] %s", err.Line, err.Column, err.Msg)
+	return err.Diagnostic().Error()
+}
+
+// Diagnostic implements the diag.Diagnoser interface
+func (err Error) Diagnostic() diag.Diagnostic {
+	return diag.New(diag.Error, err.Line, err.Column, " ", err.Msg)
 }
 
 var keywords = map[string]Type{
-	"true":   True,
-	"false":  False,
-	"nil":    Nil,
-	"print":  Print,
-	"get":    Get,
-	"post":   Post,
-	"return": Return,
+	"true":     True,
+	"false":    False,
+	"nil":      Nil,
+	"print":    Print,
+	"emit":     Emit,
+	"get":      Get,
+	"post":     Post,
+	"put":      Put,
+	"patch":    Patch,
+	"delete":   Delete,
+	"head":     Head,
+	"return":   Return,
+	"and":      And,
+	"or":       Or,
+	"not":      Not,
+	"if":       If,
+	"else":     Else,
+	"for":      For,
+	"in":       In,
+	"while":    While,
+	"break":    Break,
+	"continue": Continue,
+	"try":      Try,
+	"catch":    Catch,
 }
 
 // Scanner given a byte string will go through each byte character and tokenize them
 type Scanner struct {
-	start   int
-	current int
-	line    int
-	column  int
-	src     []byte
-	length  int
-	tokens  Tokens
+	start    int
+	current  int
+	line     int
+	column   int
+	src      []byte
+	length   int
+	tokens   Tokens
+	comments bool
+}
+
+// Option configures a scanner
+type Option func(*Scanner)
+
+// WithComments makes the scanner emit Comment tokens instead of discarding comments e.g so that a
+// formatter can preserve them
+func WithComments() Option {
+	return func(s *Scanner) {
+		s.comments = true
+	}
 }
 
 // NewScanner initializes a new scanner
-func NewScanner(src []byte) *Scanner {
-	return &Scanner{src: src, length: len(src)}
+func NewScanner(src []byte, opts ...Option) *Scanner {
+	s := &Scanner{src: src, length: len(src)}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
 }
 
 // ScanTokens goes through the provided src string and performs lexing
@@ -149,23 +198,77 @@ func (s *Scanner) scanToken() {
 		s.add(Tilde, "~")
 		s.column++
 	case '=':
-		s.add(Equal, "=")
+		if s.match('=') {
+			s.add(EqualEqual, "==")
+			s.column += 2
+		} else {
+			s.add(Equal, "=")
+			s.column++
+		}
+	case '!':
+		if !s.match('=') {
+			panic(Error{
+				Line:   s.line,
+				Column: s.column,
+				Msg:    "expects '=' after '!'",
+			})
+		}
+		s.add(BangEqual, "!=")
+		s.column += 2
+	case '<':
+		if s.match('=') {
+			s.add(LessEqual, "<=")
+			s.column += 2
+		} else {
+			s.add(Less, "<")
+			s.column++
+		}
+	case '>':
+		if s.match('=') {
+			s.add(GreaterEqual, ">=")
+			s.column += 2
+		} else {
+			s.add(Greater, ">")
+			s.column++
+		}
+	case '+':
+		s.add(Plus, "+")
+		s.column++
+	case '*':
+		s.add(Star, "*")
+		s.column++
+	case '/':
+		if s.match('/') {
+			s.lineComment()
+		} else if s.match('*') {
+			s.blockComment()
+		} else {
+			s.add(Slash, "/")
+			s.column++
+		}
+	case '#':
+		s.lineComment()
+	case '%':
+		s.add(Percent, "%")
 		s.column++
 	case '\'':
 		s.addString('\'')
 	case '"':
 		s.addString('"')
+	case '`':
+		s.addRawString()
 	case '@':
 		s.identifier()
 	case '-':
-		if s.peek() == '>' {
-			s.advance()
+		if s.match('>') {
 			s.add(Arrow, "->")
 			s.column += 2
 		} else {
 			s.add(Minus, "-")
+			s.column++
 		}
-	case ' ':
+	case ' ', '\t', '\r':
+		// A tab counts as a single column, same as any other character
 		s.column++
 	case '\n':
 		s.add(Newline, "\n")
@@ -203,31 +306,212 @@ func (s *Scanner) addToken(t *Token) {
 	s.tokens = append(s.tokens, t)
 }
 
+// addString scans a string literal delimited by either single or double quotes. Escape sequences
+// are decoded into the token literal. Three consecutive delimiters start a multiline string that is
+// terminated by another three delimiters, a newline directly after the opening delimiters is not
+// part of the string.
+// Double quoted strings can embed expressions within `${` and `}`, which are scanned as interpolation
+// tokens surrounding the tokens of the embedded expressions
 func (s *Scanner) addString(delimiter byte) {
-	for !s.isAtEnd() {
+	multiline := s.matchAll(delimiter, delimiter)
+	if multiline {
+		// Skip the newline that directly follows the opening delimiters
+		if !s.match('\n') {
+			s.matchAll('\r', '\n')
+		}
+	}
+
+	buf := &strings.Builder{}
+	interpolated := false
+	for {
+		if s.isAtEnd() {
+			s.errorAt(s.start, "unterminated string")
+		}
 		char := s.advance()
-		if char == '\n' {
-			panic(Error{
-				Line:   s.line,
-				Column: s.column,
-				Msg:    "multiline strings not supported",
-			})
+		switch {
+		case char == delimiter && (!multiline || s.matchAll(delimiter, delimiter)):
+			if interpolated {
+				s.addMultilineToken(InterpolationEnd, buf.String())
+			} else {
+				s.addMultilineToken(String, buf.String())
+			}
+			return
+		case char == '$' && delimiter == '"' && s.match('{'):
+			if interpolated {
+				s.addMultilineToken(InterpolationPart, buf.String())
+			} else {
+				s.addMultilineToken(InterpolationStart, buf.String())
+			}
+			interpolated = true
+			s.interpolation()
+			buf.Reset()
+			// The next part of the string starts at the closing '}'
+			s.start = s.current
+			s.advance()
+		case char == '\n' && !multiline:
+			s.errorAt(s.current-1, "unterminated string, use triple quotes for multiline strings")
+		case char == '\r' && multiline && s.match('\n'):
+			// Line endings of multiline strings are normalized to a newline
+			buf.WriteByte('\n')
+		case char == '\\':
+			s.escape(buf)
+		default:
+			buf.WriteByte(char)
 		}
-		if char == delimiter && s.src[s.current-2] != '\\' {
-			break
+	}
+}
+
+// interpolation scans the tokens of an expression embedded within a string up to the closing '}'
+// which is left unconsumed
+func (s *Scanner) interpolation() {
+	depth := 0
+	for {
+		s.start = s.current
+		if s.isAtEnd() {
+			s.errorAt(s.current, "unterminated string interpolation, expects '}'")
+		}
+		switch s.peek() {
+		case '{':
+			depth++
+		case '}':
+			if depth == 0 {
+				return
+			}
+			depth--
+		case '\n':
+			s.errorAt(s.current, "unterminated string interpolation, expects '}'")
 		}
+		s.scanToken()
 	}
+}
 
-	if s.previous() != delimiter {
-		panic(Error{
-			Line:   s.line,
-			Column: s.column,
-			Msg:    "unterminated string",
-		})
+// lineComment scans a comment that runs up to the end of the line
+func (s *Scanner) lineComment() {
+	for !s.isAtEnd() && s.peek() != '\n' {
+		s.advance()
+	}
+	// A comment on a line ending with CRLF shouldn't include the carriage return
+	end := s.current
+	if end > s.start && s.src[end-1] == '\r' {
+		end--
+	}
+	if s.comments {
+		s.add(Comment, string(s.src[s.start:end]))
+	}
+	s.column += s.current - s.start
+}
+
+// blockComment scans a comment delimited by '/*' and '*/' which can span multiple lines. A comment
+// spanning multiple lines separates statements the same way a newline does, so it's followed by a
+// Newline token positioned at it's last line break
+func (s *Scanner) blockComment() {
+	for !s.matchAll('*', '/') {
+		if s.isAtEnd() {
+			s.errorAt(s.start, "unterminated block comment")
+		}
+		s.advance()
+	}
+	comment := string(s.src[s.start:s.current])
+	if s.comments {
+		s.add(Comment, comment)
+	}
+	if index := strings.LastIndexByte(comment, '\n'); index >= 0 {
+		s.line, s.column = s.positionOf(s.start + index)
+		s.add(Newline, "\n")
+		s.start += index
+	}
+	s.line, s.column = s.positionOf(s.current)
+}
+
+// addRawString scans a string literal delimited by backticks. Raw strings can span multiple lines and
+// don't support escape sequences. CRLF line endings are normalized to a newline
+func (s *Scanner) addRawString() {
+	for !s.isAtEnd() && s.peek() != '`' {
+		s.advance()
+	}
+	if !s.match('`') {
+		s.errorAt(s.start, "unterminated raw string")
+	}
+	literal := strings.ReplaceAll(string(s.src[s.start+1:s.current-1]), "\r\n", "\n")
+	s.addMultilineToken(String, literal)
+}
+
+// escape decodes the escape sequence that follows a backslash into the buffer
+func (s *Scanner) escape(buf *strings.Builder) {
+	if s.isAtEnd() {
+		s.errorAt(s.current-1, "unterminated escape sequence")
 	}
+	char := s.advance()
+	switch char {
+	case 'n':
+		buf.WriteByte('\n')
+	case 't':
+		buf.WriteByte('\t')
+	case 'r':
+		buf.WriteByte('\r')
+	case '0':
+		buf.WriteByte(0)
+	case '\\', '\'', '"', '`', '$':
+		buf.WriteByte(char)
+	case 'u':
+		if s.current+4 > s.length {
+			s.errorAt(s.current-2, "expects 4 hex digits after '\\u'")
+		}
+		code, err := strconv.ParseUint(string(s.src[s.current:s.current+4]), 16, 32)
+		if err != nil {
+			s.errorAt(s.current-2, "expects 4 hex digits after '\\u'")
+		}
+		s.current += 4
+		buf.WriteRune(rune(code))
+	default:
+		s.errorAt(s.current-2, fmt.Sprintf("unknown escape sequence '\\%c'", char))
+	}
+}
+
+// addMultilineToken adds a token that spans from the start of the current lexeme and updates the
+// line and column to the end of the lexeme, which can span multiple lines
+func (s *Scanner) addMultilineToken(typ Type, literal interface{}) {
 	lexeme := string(s.src[s.start:s.current])
-	s.add(String, lexeme, lexeme[1:len(lexeme)-1])
-	s.column += len(lexeme)
+	s.add(typ, lexeme, literal)
+	s.line, s.column = s.positionOf(s.current)
+}
+
+// positionOf returns the line and column of an offset within the current lexeme
+func (s *Scanner) positionOf(offset int) (line, column int) {
+	line, column = s.line, s.column
+	for _, char := range s.src[s.start:offset] {
+		if char == '\n' {
+			line++
+			column = 0
+		} else {
+			column++
+		}
+	}
+	return line, column
+}
+
+// errorAt panics with a scanner error positioned at the offset within the current lexeme
+func (s *Scanner) errorAt(offset int, msg string) {
+	line, column := s.positionOf(offset)
+	panic(Error{
+		Line:   line,
+		Column: column,
+		Msg:    msg,
+	})
+}
+
+// matchAll consumes the next characters only if all of them match the expected ones
+func (s *Scanner) matchAll(expected ...byte) bool {
+	if s.current+len(expected) > s.length {
+		return false
+	}
+	for index, char := range expected {
+		if s.src[s.current+index] != char {
+			return false
+		}
+	}
+	s.current += len(expected)
+	return true
 }
 
 func (s *Scanner) isAlpha(char byte) bool {
@@ -310,3 +594,12 @@ func (s *Scanner) number() {
 func (s *Scanner) peek() byte {
 	return s.src[s.current]
 }
+
+// match consumes the next character if it's the expected one
+func (s *Scanner) match(expected byte) bool {
+	if s.isAtEnd() || s.peek() != expected {
+		return false
+	}
+	s.current++
+	return true
+}
@@ -0,0 +1,183 @@
+package token
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+// scan returns the tokens of the source, without the EOF token, as "Type" or "Type literal" with
+// the literal in go syntax
+func scan(t *testing.T, src string) []string {
+	t.Helper()
+	tokens, err := NewScanner([]byte(src)).ScanTokens()
+	if err != nil {
+		t.Fatalf("scanning the source: %s", err)
+	}
+	var scanned []string
+	for _, token := range tokens[:len(tokens)-1] {
+		if token.Literal != nil {
+			scanned = append(scanned, fmt.Sprintf("%s %#v", token.Type, token.Literal))
+		} else {
+			scanned = append(scanned, token.Type.String())
+		}
+	}
+	return scanned
+}
+
+func TestScanStrings(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want []string
+	}{
+		{"single quotes", `'a "b"'`, []string{`String "a \"b\""`}},
+		{"double quotes", `"a 'b'"`, []string{`String "a 'b'"`}},
+		{"escapes", `'\n\t\r\\\'\"\$\0'`, []string{`String "\n\t\r\\'\"$\x00"`}},
+		{"unicode escape", `"caf\u00e9"`, []string{`String "café"`}},
+		{"escaped interpolation", `"\${a}"`, []string{`String "${a}"`}},
+		{"single quotes don't interpolate", `'${a}'`, []string{`String "${a}"`}},
+		{"multiline", "\"\"\"\nfirst\n  second \"quoted\"\n\"\"\"", []string{`String "first\n  second \"quoted\"\n"`}},
+		{"multiline with CRLF", "'''\r\nfirst\r\nsecond'''", []string{`String "first\nsecond"`}},
+		{"raw", "`C:\\path\\n${a}`", []string{`String "C:\\path\\n${a}"`}},
+		{"raw multiline", "`first\r\nsecond`", []string{`String "first\nsecond"`}},
+		{"raw with quotes", "`'a' \"b\"`", []string{`String "'a' \"b\""`}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := scan(t, test.src); !reflect.DeepEqual(got, test.want) {
+				t.Errorf("got %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestScanInterpolation(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want []string
+	}{
+		{
+			name: "single expression",
+			src:  `"a${b}c"`,
+			want: []string{`InterpolationStart "a"`, "Ident", `InterpolationEnd "c"`},
+		},
+		{
+			name: "several expressions",
+			src:  `"${a + 1}-${b}"`,
+			want: []string{`InterpolationStart ""`, "Ident", "Plus", "Number 1", `InterpolationPart "-"`, "Ident",
+				`InterpolationEnd ""`},
+		},
+		{
+			name: "nested map",
+			src:  `"${ {'k': 1}['k'] }"`,
+			want: []string{`InterpolationStart ""`, "LeftCurlyBracket", `String "k"`, "Colon", "Number 1",
+				"RightCurlyBracket", "LeftBracket", `String "k"`, "RightBracket", `InterpolationEnd ""`},
+		},
+		{
+			name: "nested interpolation",
+			src:  `"a${"b${c}"}d"`,
+			want: []string{`InterpolationStart "a"`, `InterpolationStart "b"`, "Ident", `InterpolationEnd ""`,
+				`InterpolationEnd "d"`},
+		},
+		{
+			name: "multiline",
+			src:  "\"\"\"a\n${b}\nc\"\"\"",
+			want: []string{`InterpolationStart "a\n"`, "Ident", `InterpolationEnd "\nc"`},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := scan(t, test.src); !reflect.DeepEqual(got, test.want) {
+				t.Errorf("got %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestScanErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want string
+	}{
+		{"unterminated string", `a = 'abc`, "1:5: unterminated string"},
+		{"unterminated multiline string", "a = \"\"\"abc\n\"", "1:5: unterminated string"},
+		{"newline in a string", "a = 'abc\nd'", "1:9: unterminated string, use triple quotes for multiline strings"},
+		{"unknown escape", `'\q'`, `1:2: unknown escape sequence '\q'`},
+		{"short unicode escape", `'\u12'`, `1:2: expects 4 hex digits after '\u'`},
+		{"invalid unicode escape", `'\u12zz'`, `1:2: expects 4 hex digits after '\u'`},
+		{"unterminated raw string", "a = `abc", "1:5: unterminated raw string"},
+		{"unterminated interpolation", `"a${b`, "1:6: unterminated string interpolation, expects '}'"},
+		{"interpolation across lines", "\"a${b\n}\"", "1:6: unterminated string interpolation, expects '}'"},
+		{"unterminated block comment", "a /* b", "1:3: unterminated block comment"},
+		{"unexpected character", "a ^ b", `1:3: encountered unexpected token '^'`},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := NewScanner([]byte(test.src)).ScanTokens()
+			if err == nil {
+				t.Fatalf("expected the error %q", test.want)
+			}
+			d := err.(Error).Diagnostic()
+			if got := fmt.Sprintf("%d:%d: %s", d.Start.Line+1, d.Start.Column+1, d.Msg); got != test.want {
+				t.Errorf("got the error %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestScanPositions(t *testing.T) {
+	src := "a = '''x\ny''' + `z\nw` + \"${b}\"\nc"
+	tokens, err := NewScanner([]byte(src)).ScanTokens()
+	if err != nil {
+		t.Fatalf("scanning the source: %s", err)
+	}
+	var got []string
+	for _, token := range tokens {
+		if token.Type == Ident {
+			got = append(got, fmt.Sprintf("%s %d:%d", token.Lexeme, token.Line+1, token.Column+1))
+		}
+	}
+	want := []string{"a 1:1", "b 3:9", "c 4:1"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got identifiers at %q, want %q", got, want)
+	}
+}
+
+func TestScanBlockComments(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want []string
+	}{
+		{"single line", "a /* x */ b", []string{"Ident", "Ident"}},
+		{"multiple lines", "a /* x\n y\n */ b", []string{"Ident", "Newline", "Ident"}},
+		{"followed by a newline", "a /* x\n */\nb", []string{"Ident", "Newline", "Newline", "Ident"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := scan(t, test.src); !reflect.DeepEqual(got, test.want) {
+				t.Errorf("got %q, want %q", got, test.want)
+			}
+		})
+	}
+
+	tokens, err := NewScanner([]byte("a /* x\n y\n */ b"), WithComments()).ScanTokens()
+	if err != nil {
+		t.Fatalf("scanning the source: %s", err)
+	}
+	var got []string
+	for _, token := range tokens {
+		got = append(got, fmt.Sprintf("%s %d:%d", token.Type, token.Line+1, token.Column+1))
+	}
+	want := []string{"Ident 1:1", "Comment 1:3", "Newline 2:3", "Ident 3:5", "EOF 3:6"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got tokens at %q, want %q", got, want)
+	}
+}
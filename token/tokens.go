@@ -20,21 +20,55 @@ const (
 	DoubleQuote
 	Minus
 	Arrow
+	Plus
+	Star
+	Slash
+	Percent
+	EqualEqual
+	BangEqual
+	Less
+	LessEqual
+	Greater
+	GreaterEqual
 
 	Ident
 	Tag
 
 	Print
+	Emit
 	Get
 	Post
+	Put
+	Patch
+	Delete
+	Head
 	Return
+	And
+	Or
+	Not
+	If
+	Else
+	For
+	In
+	While
+	Break
+	Continue
+	Try
+	Catch
 
 	Nil
 	True
 	False
 	String
 	Number
+	// An interpolated string e.g "a${x}b${y}c" is made up of an InterpolationStart `"a${`, an
+	// InterpolationPart `}b${` and an InterpolationEnd `}c"` with the tokens of the embedded
+	// expressions in between them
+	InterpolationStart
+	InterpolationPart
+	InterpolationEnd
 
 	Newline
+	Comment
 	EOF
 )
@@ -23,24 +23,55 @@ func _() {
 	_ = x[DoubleQuote-12]
 	_ = x[Minus-13]
 	_ = x[Arrow-14]
-	_ = x[Ident-15]
-	_ = x[Tag-16]
-	_ = x[Print-17]
-	_ = x[Get-18]
-	_ = x[Post-19]
-	_ = x[Return-20]
-	_ = x[Nil-21]
-	_ = x[True-22]
-	_ = x[False-23]
-	_ = x[String-24]
-	_ = x[Number-25]
-	_ = x[Newline-26]
-	_ = x[EOF-27]
+	_ = x[Plus-15]
+	_ = x[Star-16]
+	_ = x[Slash-17]
+	_ = x[Percent-18]
+	_ = x[EqualEqual-19]
+	_ = x[BangEqual-20]
+	_ = x[Less-21]
+	_ = x[LessEqual-22]
+	_ = x[Greater-23]
+	_ = x[GreaterEqual-24]
+	_ = x[Ident-25]
+	_ = x[Tag-26]
+	_ = x[Print-27]
+	_ = x[Emit-28]
+	_ = x[Get-29]
+	_ = x[Post-30]
+	_ = x[Put-31]
+	_ = x[Patch-32]
+	_ = x[Delete-33]
+	_ = x[Head-34]
+	_ = x[Return-35]
+	_ = x[And-36]
+	_ = x[Or-37]
+	_ = x[Not-38]
+	_ = x[If-39]
+	_ = x[Else-40]
+	_ = x[For-41]
+	_ = x[In-42]
+	_ = x[While-43]
+	_ = x[Break-44]
+	_ = x[Continue-45]
+	_ = x[Try-46]
+	_ = x[Catch-47]
+	_ = x[Nil-48]
+	_ = x[True-49]
+	_ = x[False-50]
+	_ = x[String-51]
+	_ = x[Number-52]
+	_ = x[InterpolationStart-53]
+	_ = x[InterpolationPart-54]
+	_ = x[InterpolationEnd-55]
+	_ = x[Newline-56]
+	_ = x[Comment-57]
+	_ = x[EOF-58]
 }
 
-const _Type_name = "LeftBracketRightBracketLeftParenRightParenLeftCurlyBracketRightCurlyBracketCommaPeriodColonTildeEqualSingleQuoteDoubleQuoteMinusArrowIdentTagPrintGetPostReturnNilTrueFalseStringNumberNewlineEOF"
+const _Type_name = "LeftBracketRightBracketLeftParenRightParenLeftCurlyBracketRightCurlyBracketCommaPeriodColonTildeEqualSingleQuoteDoubleQuoteMinusArrowPlusStarSlashPercentEqualEqualBangEqualLessLessEqualGreaterGreaterEqualIdentTagPrintEmitGetPostPutPatchDeleteHeadReturnAndOrNotIfElseForInWhileBreakContinueTryCatchNilTrueFalseStringNumberInterpolationStartInterpolationPartInterpolationEndNewlineCommentEOF"
 
-var _Type_index = [...]uint8{0, 11, 23, 32, 42, 58, 75, 80, 86, 91, 96, 101, 112, 123, 128, 133, 138, 141, 146, 149, 153, 159, 162, 166, 171, 177, 183, 190, 193}
+var _Type_index = [...]uint16{0, 11, 23, 32, 42, 58, 75, 80, 86, 91, 96, 101, 112, 123, 128, 133, 137, 141, 146, 153, 163, 172, 176, 185, 192, 204, 209, 212, 217, 221, 224, 228, 231, 236, 242, 246, 252, 255, 257, 260, 262, 266, 269, 271, 276, 281, 289, 292, 297, 300, 304, 309, 315, 321, 339, 356, 372, 379, 386, 389}
 
 func (i Type) String() string {
 	if i < 0 || i >= Type(len(_Type_index)-1) {